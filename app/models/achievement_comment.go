@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CommentEdited  = "edited"
+	CommentDeleted = "deleted"
+)
+
+// AchievementComment adalah satu komentar pada prestasi. Komentar yang
+// dihapus tetap ada di thread (Body kosong, DeletedAt terisi) supaya
+// balasannya tidak hilang.
+type AchievementComment struct {
+	ID            uuid.UUID  `json:"id"`
+	AchievementID uuid.UUID  `json:"achievementId"`
+	ParentID      *uuid.UUID `json:"parentId"`
+	AuthorID      uuid.UUID  `json:"authorId"`
+	AuthorName    string     `json:"authorName"`
+	Body          string     `json:"body"`
+	Internal      bool       `json:"internal"`
+	// Field / Attachment terisi kalau komentar merujuk ke bagian tertentu
+	Field      *string    `json:"field"`
+	Attachment *string    `json:"attachment"`
+	Edited     bool       `json:"edited"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	DeletedAt  *time.Time `json:"deletedAt"`
+
+	Replies []AchievementComment `json:"replies"`
+}
+
+// CommentRevision menyimpan isi komentar sebelum diedit / dihapus.
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CommentID uuid.UUID `json:"commentId"`
+	Action    string    `json:"action"`
+	Body      string    `json:"body"`
+	ChangedBy uuid.UUID `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
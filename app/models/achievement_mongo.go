@@ -22,6 +22,7 @@ type AchievementDetail struct {
 		Status    string    `bson:"status"`
 		Timestamp time.Time `bson:"timestamp"`
 		ChangedBy string    `bson:"changedBy"`
+		Note      string    `bson:"note,omitempty"`
 	} `bson:"history"`
 
 	CreatedAt time.Time `bson:"createdAt"`
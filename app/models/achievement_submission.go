@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementSnapshot adalah isi prestasi (dari Mongo) saat di-submit.
+// Dipakai juga sebagai isi AchievementVersion.
+type AchievementSnapshot struct {
+	Title       string   `json:"title" bson:"title"`
+	Description string   `json:"description" bson:"description"`
+	Category    string   `json:"category" bson:"category"`
+	Level       string   `json:"level" bson:"level"`
+	EventDate   string   `json:"eventDate" bson:"eventDate"`
+	Attachments []string `json:"attachments" bson:"attachments"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// AchievementSubmission adalah satu round submit -> review. Outcome kosong
+// berarti round masih menunggu review.
+type AchievementSubmission struct {
+	ID            uuid.UUID           `json:"id"`
+	AchievementID uuid.UUID           `json:"achievementId"`
+	Round         int                 `json:"round"`
+	Snapshot      AchievementSnapshot `json:"snapshot"`
+	// Changes dibanding round sebelumnya
+	Changes      []FieldChange `json:"changes"`
+	SubmittedBy  uuid.UUID     `json:"submittedBy"`
+	SubmittedAt  time.Time     `json:"submittedAt"`
+	Outcome      *string       `json:"outcome"`
+	ReviewedBy   *uuid.UUID    `json:"reviewedBy"`
+	ReviewedAt   *time.Time    `json:"reviewedAt"`
+	DelegationID *uuid.UUID    `json:"delegationId"`
+	Note         *string       `json:"note"`
+}
+
+func (d AchievementDetail) Snapshot() AchievementSnapshot {
+	attachments := d.Attachments
+	if attachments == nil {
+		attachments = []string{}
+	}
+
+	return AchievementSnapshot{
+		Title:       d.Title,
+		Description: d.Description,
+		Category:    d.Category,
+		Level:       d.Level,
+		EventDate:   d.EventDate,
+		Attachments: attachments,
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	VersionCreated         = "created"
+	VersionUpdated         = "updated"
+	VersionAttachmentAdded = "attachment-added"
+	// VersionBaseline: isi prestasi lama yang belum punya versi, disimpan
+	// sebelum perubahan pertama supaya tidak hilang.
+	VersionBaseline = "baseline"
+)
+
+// AchievementVersion adalah salinan isi prestasi setelah satu perubahan.
+// Dokumen ini tidak pernah diubah lagi.
+type AchievementVersion struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	AchievementID string              `bson:"achievementId" json:"achievementId"` // hex id dokumen achievements
+	Version       int                 `bson:"version" json:"version"`
+	Change        string              `bson:"change" json:"change"`
+	Content       AchievementSnapshot `bson:"content" json:"content"`
+	AuthorID      string              `bson:"authorId" json:"authorId"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+}
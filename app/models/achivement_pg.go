@@ -15,8 +15,12 @@ type AchievementRef struct {
 	SubmittedAt *time.Time `json:"submittedAt"`
 	VerifiedAt  *time.Time `json:"verifiedAt"`
 	VerifiedBy  *uuid.UUID `json:"verifiedBy"`
+	// DelegationID terisi kalau verify / reject dilakukan lewat delegasi
+	DelegationID *uuid.UUID `json:"delegationId"`
 
-	RejectionNote *string   `json:"rejectionNote"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	RejectionNote *string `json:"rejectionNote"`
+	// RevisionNote adalah feedback reviewer saat status revision_requested
+	RevisionNote *string   `json:"revisionNote"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
 }
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cakupan reviewer sebuah stage, selain permission-nya
+const (
+	// StageScopeAdvisor: dosen wali mahasiswa (atau penggantinya lewat delegasi)
+	StageScopeAdvisor = "advisor"
+	// StageScopeDepartment: pemegang permission di departemen yang sama
+	StageScopeDepartment = "department"
+	// StageScopeGlobal: semua pemegang permission (tetap dibatasi unit organisasinya)
+	StageScopeGlobal = "global"
+)
+
+// ApprovalChain berlaku untuk prestasi dengan Level / Category tersebut.
+// Nil berarti berlaku untuk semua nilai.
+type ApprovalChain struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Level     *string         `json:"level"`
+	Category  *string         `json:"category"`
+	Stages    []ApprovalStage `json:"stages"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+type ApprovalStage struct {
+	ID         uuid.UUID `json:"id"`
+	Position   int       `json:"position"`
+	Name       string    `json:"name"`
+	Permission string    `json:"permission"`
+	Scope      string    `json:"scope"`
+}
+
+// AchievementApproval adalah stage yang harus dilalui satu round submit.
+// ApprovedAt kosong berarti stage tersebut belum disetujui.
+type AchievementApproval struct {
+	ID            uuid.UUID `json:"id"`
+	SubmissionID  uuid.UUID `json:"submissionId"`
+	AchievementID uuid.UUID `json:"achievementId"`
+	Position      int       `json:"position"`
+	Name          string    `json:"name"`
+	Permission    string    `json:"permission"`
+	Scope         string    `json:"scope"`
+	// Final: stage terakhir; persetujuannya membuat prestasi verified
+	Final        bool       `json:"final"`
+	ApprovedBy   *uuid.UUID `json:"approvedBy"`
+	ApprovedAt   *time.Time `json:"approvedAt"`
+	DelegationID *uuid.UUID `json:"delegationId"`
+	Note         *string    `json:"note"`
+}
+
+// ApprovalQueueItem adalah prestasi yang sedang menunggu di suatu stage.
+type ApprovalQueueItem struct {
+	Achievement AchievementRef      `json:"achievement"`
+	Stage       AchievementApproval `json:"stage"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog mencatat request yang dilakukan selama impersonation:
+// UserID adalah user yang "dilihat", ImpersonatorID admin yang sebenarnya.
+type AuditLog struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"userId"`
+	ImpersonatorID *uuid.UUID `json:"impersonatorId"`
+	Method         string     `json:"method"`
+	Path           string     `json:"path"`
+	Status         int        `json:"status"`
+	IP             string     `json:"ip"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationDelegation memberi DelegateID hak dosen wali milik
+// DelegatorID (keduanya lecturers.id) selama StartsAt..EndsAt.
+type VerificationDelegation struct {
+	ID          uuid.UUID  `json:"id"`
+	DelegatorID uuid.UUID  `json:"delegatorId"`
+	DelegateID  uuid.UUID  `json:"delegateId"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      time.Time  `json:"endsAt"`
+	Reason      string     `json:"reason"`
+	CreatedBy   uuid.UUID  `json:"createdBy"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// Active: belum dicabut dan sekarang berada di dalam masa berlakunya.
+func (d VerificationDelegation) Active(now time.Time) bool {
+	return d.RevokedAt == nil && !now.Before(d.StartsAt) && now.Before(d.EndsAt)
+}
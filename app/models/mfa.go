@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserMFA struct {
+	UserID       uuid.UUID  `json:"userId"`
+	Secret       string     `json:"-"`
+	Enabled      bool       `json:"enabled"`
+	EnabledAt    *time.Time `json:"enabledAt"`
+	LastUsedStep *int64     `json:"-"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
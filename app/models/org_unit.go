@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	OrgUnitUniversity = "university"
+	OrgUnitFaculty    = "faculty"
+	OrgUnitDepartment = "department"
+)
+
+type OrgUnit struct {
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parentId"`
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "github.com/google/uuid"
+
+type Permission struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"isSystem"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenFamily struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"userId"`
+	IP            string     `json:"ip"`
+	UserAgent     string     `json:"userAgent"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastUsedAt    time.Time  `json:"lastUsedAt"`
+	RevokedAt     *time.Time `json:"revokedAt"`
+	RevokedReason *string    `json:"revokedReason"`
+}
+
+type RefreshToken struct {
+	JTI       uuid.UUID  `json:"jti"`
+	FamilyID  uuid.UUID  `json:"familyId"`
+	UserID    uuid.UUID  `json:"userId"`
+	IssuedAt  time.Time  `json:"issuedAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RegistrationPendingVerification = "pending_verification"
+	RegistrationPendingApproval     = "pending_approval"
+	RegistrationApproved            = "approved"
+	RegistrationRejected            = "rejected"
+)
+
+// StudentRegistration adalah pendaftaran mandiri mahasiswa yang menunggu
+// verifikasi email lalu persetujuan admin.
+type StudentRegistration struct {
+	ID              uuid.UUID  `json:"id"`
+	FullName        string     `json:"fullName"`
+	Email           string     `json:"email"`
+	StudentID       string     `json:"studentId"`
+	ProgramStudy    string     `json:"programStudy"`
+	AcademicYear    string     `json:"academicYear"`
+	PasswordHash    string     `json:"-"`
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	ReviewedBy      *uuid.UUID `json:"reviewedBy"`
+	ReviewedAt      *time.Time `json:"reviewedAt"`
+	RejectReason    *string    `json:"rejectReason"`
+	UserID          *uuid.UUID `json:"userId"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
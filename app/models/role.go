@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+type Role struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	MFARequired bool      `json:"mfaRequired"`
+	IsSystem    bool      `json:"isSystem"`
+}
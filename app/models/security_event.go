@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SecurityEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"userId"`
+	EventType string     `json:"eventType"`
+	IP        string     `json:"ip"`
+	UserAgent string     `json:"userAgent"`
+	Detail    string     `json:"detail"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -3,11 +3,12 @@ package models
 import "github.com/google/uuid"
 
 type Student struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"userId"`
-	StudentID    string    `json:"studentId"`
-	ProgramStudy string    `json:"programStudy"`
-	AcademicYear string    `json:"academicYear"`
-	AdvisorID    uuid.UUID `json:"advisorId"`
-	CreatedAt    string    `json:"createdAt"`
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"userId"`
+	StudentID    string     `json:"studentId"`
+	ProgramStudy string     `json:"programStudy"`
+	AcademicYear string     `json:"academicYear"`
+	AdvisorID    uuid.UUID  `json:"advisorId"`
+	OrgUnitID    *uuid.UUID `json:"orgUnitId"`
+	CreatedAt    string     `json:"createdAt"`
 }
@@ -1,13 +1,22 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Users struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	FullName     string    `json:"fullName"`
-	RoleID       uuid.UUID `json:"roleId"`
-	IsActive     bool      `json:"isActive"`
+	ID                uuid.UUID  `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	PasswordHash      string     `json:"-"`
+	FullName          string     `json:"fullName"`
+	RoleID            uuid.UUID  `json:"roleId"`
+	IsActive          bool       `json:"isActive"`
+	FailedLoginCount  int        `json:"-"`
+	LastFailedLoginAt *time.Time `json:"-"`
+	LockedUntil       *time.Time `json:"lockedUntil"`
+	TokenVersion      int        `json:"-"`
+	ScopeOrgUnitID    *uuid.UUID `json:"scopeOrgUnitId"`
 }
@@ -0,0 +1,234 @@
+// Package policy berisi aturan otorisasi level resource (siapa boleh
+// melakukan apa terhadap prestasi / data mahasiswa tertentu). RBACMiddleware
+// hanya mengecek permission umum; pengecekan kepemilikan ada di sini.
+package policy
+
+import (
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionSubmit Action = "submit"
+	ActionUpload Action = "upload"
+	ActionVerify Action = "verify"
+	ActionReject Action = "reject"
+	// ActionRequestRevision: kembalikan prestasi ke mahasiswa dengan feedback.
+	ActionRequestRevision Action = "request_revision"
+	// ActionManage: ubah data administratif mahasiswa (mis. dosen wali).
+	ActionManage Action = "manage"
+	// ActionDelegate: memberi delegasi verifikasi atas nama dosen tersebut.
+	ActionDelegate Action = "delegate"
+	// ActionComment: membaca dan menulis komentar pada prestasi.
+	ActionComment Action = "comment"
+	// ActionCommentInternal: komentar internal yang hanya terlihat reviewer.
+	ActionCommentInternal Action = "comment_internal"
+)
+
+const (
+	KindAchievement = "achievement"
+	KindStudent     = "student"
+	KindLecturer    = "lecturer"
+)
+
+const (
+	// PermSuperAdmin memberi akses ke seluruh data.
+	PermSuperAdmin = "user:manage"
+	// PermDepartmentAdmin memberi akses ke mahasiswa di org unit departemen
+	// actor (beserta turunannya).
+	PermDepartmentAdmin = "achievement:department"
+)
+
+// Actor adalah user yang melakukan request beserta profil akademiknya.
+type Actor struct {
+	UserID      uuid.UUID
+	StudentID   uuid.UUID // students.id, kosong kalau bukan mahasiswa
+	LecturerID  uuid.UUID // lecturers.id, kosong kalau bukan dosen
+	Permissions map[string]bool
+	// OrgUnits diisi kalau role actor dibatasi ke unit tertentu (beserta
+	// turunannya); nil berarti berlaku untuk seluruh universitas.
+	OrgUnits []uuid.UUID
+	// DepartmentUnits: org unit departemen actor beserta turunannya, dari
+	// scope role atau unit dosen; nil kalau actor tidak berada di departemen.
+	DepartmentUnits []uuid.UUID
+	// Delegations: lecturers.id pemberi delegasi -> delegasi untuk actor.
+	// Masa berlaku dicek ulang di Can, jadi delegasi yang berakhir di tengah
+	// request (mis. bulk review) tidak ikut terpakai.
+	Delegations map[uuid.UUID]models.VerificationDelegation
+	// ServiceAccount: request memakai API key. Permission berasal dari scopes
+	// key; service account tidak pernah super admin dan tidak bisa mereview,
+	// karena verified_by / approver harus berisi user.
+	ServiceAccount bool
+}
+
+// Resource menjelaskan data yang diakses. Untuk prestasi, StudentID adalah
+// pemilik prestasi; AdvisorID dan OrgUnitID diambil dari mahasiswa tersebut.
+// Untuk KindLecturer, AdvisorID adalah dosen itu sendiri.
+type Resource struct {
+	Kind      string
+	StudentID uuid.UUID
+	AdvisorID uuid.UUID
+	OrgUnitID *uuid.UUID
+	// Stage terisi kalau prestasi sedang menunggu stage approval chain;
+	// review hanya boleh dilakukan oleh reviewer stage tersebut.
+	Stage *Stage
+}
+
+// Stage adalah syarat reviewer stage approval yang sedang aktif.
+type Stage struct {
+	Permission string
+	Scope      string // models.StageScope*
+}
+
+func (a Actor) IsSuperAdmin() bool {
+	return !a.ServiceAccount && a.Permissions[PermSuperAdmin] && a.OrgUnits == nil
+}
+
+// isUnitAdmin: admin (user:manage) yang role-nya di-scope ke unit resource.
+func (a Actor) isUnitAdmin(r Resource) bool {
+	return a.Permissions[PermSuperAdmin] && a.inOrgUnits(r)
+}
+
+func (a Actor) inOrgUnits(r Resource) bool {
+	return containsUnit(a.OrgUnits, r.OrgUnitID)
+}
+
+func (a Actor) inDepartment(r Resource) bool {
+	return containsUnit(a.DepartmentUnits, r.OrgUnitID)
+}
+
+func containsUnit(units []uuid.UUID, unitID *uuid.UUID) bool {
+	if unitID == nil {
+		return false
+	}
+	for _, id := range units {
+		if id == *unitID {
+			return true
+		}
+	}
+	return false
+}
+
+func (a Actor) isOwner(r Resource) bool {
+	return a.StudentID != uuid.Nil && a.StudentID == r.StudentID
+}
+
+func (a Actor) isAdvisor(r Resource) bool {
+	return a.LecturerID != uuid.Nil && a.LecturerID == r.AdvisorID
+}
+
+func (a Actor) delegationFor(r Resource) (uuid.UUID, bool) {
+	if r.AdvisorID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	d, ok := a.Delegations[r.AdvisorID]
+	if !ok || !d.Active(time.Now()) {
+		return uuid.Nil, false
+	}
+	return d.ID, true
+}
+
+func (a Actor) isDepartmentAdmin(r Resource) bool {
+	return a.Permissions[PermDepartmentAdmin] && a.inDepartment(r)
+}
+
+// Can mengembalikan true kalau actor boleh melakukan action terhadap resource.
+//
+//	owner (mahasiswa)      : read, update, delete, submit, upload, comment prestasi sendiri; read profil sendiri
+//	dosen wali             : read, verify, reject, request revision prestasi & read profil mahasiswa bimbingan
+//	dosen pengganti        : sama seperti dosen wali selama delegasinya berlaku
+//	reviewer stage         : read, verify, reject, request revision selama prestasi ada di stage-nya
+//	admin departemen       : read, verify, reject untuk mahasiswa di org unit departemennya
+//	admin unit             : seperti super admin, terbatas pada unit organisasinya
+//	super admin            : read, verify, reject semua prestasi; read & manage semua mahasiswa
+//	semua reviewer di atas : comment, termasuk comment internal
+//	service account        : tidak pernah reviewer maupun super admin
+//
+// Kalau prestasi memakai approval chain, verify / reject / request revision
+// hanya untuk reviewer stage yang sedang aktif (lihat canApproveStage).
+// Aturan status (mis. hanya draft yang boleh diubah) tetap dicek di service.
+func Can(actor Actor, action Action, res Resource) bool {
+	switch res.Kind {
+	case KindAchievement:
+		switch action {
+		case ActionUpdate, ActionDelete, ActionSubmit, ActionUpload:
+			return actor.isOwner(res)
+		case ActionRead:
+			return actor.isOwner(res) || canReview(actor, res) || canApproveStage(actor, res)
+		case ActionComment:
+			return actor.isOwner(res) || canReview(actor, res) || canApproveStage(actor, res)
+		case ActionCommentInternal:
+			return canReview(actor, res) || canApproveStage(actor, res)
+		case ActionVerify, ActionReject, ActionRequestRevision:
+			if res.Stage != nil {
+				return canApproveStage(actor, res)
+			}
+			return canReview(actor, res)
+		}
+
+	case KindStudent:
+		switch action {
+		case ActionRead:
+			return actor.isOwner(res) || canReview(actor, res)
+		case ActionManage:
+			return actor.IsSuperAdmin() || actor.isUnitAdmin(res)
+		}
+
+	case KindLecturer:
+		if action == ActionDelegate {
+			return actor.isAdvisor(res) || actor.IsSuperAdmin() || actor.isUnitAdmin(res)
+		}
+	}
+
+	return false
+}
+
+func canReview(actor Actor, res Resource) bool {
+	if actor.ServiceAccount {
+		return false
+	}
+	_, delegated := actor.delegationFor(res)
+	return actor.IsSuperAdmin() || actor.isUnitAdmin(res) ||
+		actor.isAdvisor(res) || actor.isDepartmentAdmin(res) || delegated
+}
+
+// canApproveStage: actor punya permission stage aktif dan berada dalam
+// cakupan stage tersebut (dosen wali, org unit departemen yang sama, atau semua).
+func canApproveStage(actor Actor, res Resource) bool {
+	if res.Stage == nil || actor.ServiceAccount {
+		return false
+	}
+	if actor.IsSuperAdmin() {
+		return true
+	}
+	if !actor.Permissions[res.Stage.Permission] {
+		return false
+	}
+
+	switch res.Stage.Scope {
+	case models.StageScopeAdvisor:
+		return canReview(actor, res)
+	case models.StageScopeDepartment:
+		return actor.isUnitAdmin(res) || actor.inDepartment(res)
+	case models.StageScopeGlobal:
+		return actor.OrgUnits == nil || actor.inOrgUnits(res)
+	}
+	return false
+}
+
+// ViaDelegation mengembalikan id delegasi kalau actor hanya bisa mereview
+// resource lewat delegasi, supaya service bisa mencatatnya.
+func ViaDelegation(actor Actor, res Resource) (uuid.UUID, bool) {
+	if actor.IsSuperAdmin() || actor.isUnitAdmin(res) ||
+		actor.isAdvisor(res) || actor.isDepartmentAdmin(res) {
+		return uuid.Nil, false
+	}
+	return actor.delegationFor(res)
+}
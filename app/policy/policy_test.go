@@ -0,0 +1,217 @@
+package policy
+
+import (
+	"testing"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCan(t *testing.T) {
+	var (
+		// fakultas -> departemen -> prodi, plus departemen lain
+		faculty   = uuid.New()
+		dept      = uuid.New()
+		prodi     = uuid.New()
+		otherDept = uuid.New()
+
+		studentID  = uuid.New()
+		advisorID  = uuid.New()
+		delegateID = uuid.New()
+		now        = time.Now()
+	)
+
+	achievement := Resource{Kind: KindAchievement, StudentID: studentID, AdvisorID: advisorID, OrgUnitID: &prodi}
+	otherAchievement := Resource{Kind: KindAchievement, StudentID: uuid.New(), AdvisorID: uuid.New(), OrgUnitID: &otherDept}
+	student := Resource{Kind: KindStudent, StudentID: studentID, AdvisorID: advisorID, OrgUnitID: &prodi}
+	otherStudent := Resource{Kind: KindStudent, StudentID: uuid.New(), AdvisorID: uuid.New(), OrgUnitID: &otherDept}
+	lecturer := Resource{Kind: KindLecturer, AdvisorID: advisorID, OrgUnitID: &dept}
+
+	withStage := func(r Resource, permission string, scope string) Resource {
+		r.Stage = &Stage{Permission: permission, Scope: scope}
+		return r
+	}
+	deptStage := withStage(achievement, "achievement:approve_department", models.StageScopeDepartment)
+	affairsStage := withStage(achievement, "achievement:approve_affairs", models.StageScopeGlobal)
+	advisorStage := withStage(achievement, "achievement:verify", models.StageScopeAdvisor)
+
+	perms := func(names ...string) map[string]bool {
+		m := make(map[string]bool, len(names))
+		for _, n := range names {
+			m[n] = true
+		}
+		return m
+	}
+	delegation := func(startsAt time.Time, endsAt time.Time) map[uuid.UUID]models.VerificationDelegation {
+		return map[uuid.UUID]models.VerificationDelegation{
+			advisorID: {ID: uuid.New(), DelegatorID: advisorID, DelegateID: delegateID, StartsAt: startsAt, EndsAt: endsAt},
+		}
+	}
+	revokedAt := now.Add(-time.Minute)
+
+	var (
+		owner         = Actor{UserID: uuid.New(), StudentID: studentID}
+		otherStudentA = Actor{UserID: uuid.New(), StudentID: uuid.New()}
+		advisor       = Actor{UserID: uuid.New(), LecturerID: advisorID, Permissions: perms("achievement:verify")}
+		otherLecturer = Actor{UserID: uuid.New(), LecturerID: uuid.New(), Permissions: perms("achievement:verify")}
+		deptAdmin     = Actor{UserID: uuid.New(), Permissions: perms(PermDepartmentAdmin), DepartmentUnits: []uuid.UUID{dept, prodi}}
+		// permission departemen tanpa org unit departemen tidak memberi akses apa pun
+		deptAdminNoUnit = Actor{UserID: uuid.New(), Permissions: perms(PermDepartmentAdmin)}
+		unitAdmin       = Actor{UserID: uuid.New(), Permissions: perms(PermSuperAdmin), OrgUnits: []uuid.UUID{dept, prodi}}
+		superAdmin      = Actor{UserID: uuid.New(), Permissions: perms(PermSuperAdmin)}
+		delegate        = Actor{UserID: uuid.New(), LecturerID: delegateID, Permissions: perms("achievement:verify"), Delegations: delegation(now.Add(-time.Hour), now.Add(time.Hour))}
+		expiredDelegate = Actor{UserID: uuid.New(), LecturerID: delegateID, Delegations: delegation(now.Add(-48*time.Hour), now.Add(-24*time.Hour))}
+		futureDelegate  = Actor{UserID: uuid.New(), LecturerID: delegateID, Delegations: delegation(now.Add(time.Hour), now.Add(48*time.Hour))}
+		revokedDelegate = func() Actor {
+			a := Actor{UserID: uuid.New(), LecturerID: delegateID, Delegations: delegation(now.Add(-time.Hour), now.Add(time.Hour))}
+			d := a.Delegations[advisorID]
+			d.RevokedAt = &revokedAt
+			a.Delegations[advisorID] = d
+			return a
+		}()
+		deptApprover      = Actor{UserID: uuid.New(), Permissions: perms("achievement:approve_department"), DepartmentUnits: []uuid.UUID{dept, prodi}}
+		otherDeptApprover = Actor{UserID: uuid.New(), Permissions: perms("achievement:approve_department"), DepartmentUnits: []uuid.UUID{otherDept}}
+		affairsApprover   = Actor{UserID: uuid.New(), Permissions: perms("achievement:approve_affairs")}
+		facultyAffairs    = Actor{UserID: uuid.New(), Permissions: perms("achievement:approve_affairs"), OrgUnits: []uuid.UUID{faculty, dept, prodi}}
+		otherFacAffairs   = Actor{UserID: uuid.New(), Permissions: perms("achievement:approve_affairs"), OrgUnits: []uuid.UUID{otherDept}}
+	)
+
+	tests := []struct {
+		name   string
+		actor  Actor
+		action Action
+		res    Resource
+		want   bool
+	}{
+		// owner
+		{"owner reads own achievement", owner, ActionRead, achievement, true},
+		{"owner updates own achievement", owner, ActionUpdate, achievement, true},
+		{"owner deletes own achievement", owner, ActionDelete, achievement, true},
+		{"owner submits own achievement", owner, ActionSubmit, achievement, true},
+		{"owner uploads to own achievement", owner, ActionUpload, achievement, true},
+		{"owner comments on own achievement", owner, ActionComment, achievement, true},
+		{"owner reads own profile", owner, ActionRead, student, true},
+		{"owner cannot verify own achievement", owner, ActionVerify, achievement, false},
+		{"owner cannot reject own achievement", owner, ActionReject, achievement, false},
+		{"owner cannot request revision", owner, ActionRequestRevision, achievement, false},
+		{"owner cannot comment internally", owner, ActionCommentInternal, achievement, false},
+		{"owner cannot manage own profile", owner, ActionManage, student, false},
+		{"other student cannot read", otherStudentA, ActionRead, achievement, false},
+		{"other student cannot update", otherStudentA, ActionUpdate, achievement, false},
+		{"other student cannot delete", otherStudentA, ActionDelete, achievement, false},
+		{"other student cannot submit", otherStudentA, ActionSubmit, achievement, false},
+		{"other student cannot upload", otherStudentA, ActionUpload, achievement, false},
+		{"other student cannot comment", otherStudentA, ActionComment, achievement, false},
+
+		// dosen wali
+		{"advisor reads advisee achievement", advisor, ActionRead, achievement, true},
+		{"advisor verifies advisee achievement", advisor, ActionVerify, achievement, true},
+		{"advisor rejects advisee achievement", advisor, ActionReject, achievement, true},
+		{"advisor requests revision", advisor, ActionRequestRevision, achievement, true},
+		{"advisor comments internally", advisor, ActionCommentInternal, achievement, true},
+		{"advisor reads advisee profile", advisor, ActionRead, student, true},
+		{"advisor delegates own advisees", advisor, ActionDelegate, lecturer, true},
+		{"advisor cannot update advisee achievement", advisor, ActionUpdate, achievement, false},
+		{"advisor cannot manage advisee", advisor, ActionManage, student, false},
+		{"other lecturer cannot verify", otherLecturer, ActionVerify, achievement, false},
+		{"other lecturer cannot read", otherLecturer, ActionRead, achievement, false},
+		{"other lecturer cannot delegate", otherLecturer, ActionDelegate, lecturer, false},
+
+		// admin departemen (dari org unit)
+		{"department admin reads achievement in sub unit", deptAdmin, ActionRead, achievement, true},
+		{"department admin verifies in department", deptAdmin, ActionVerify, achievement, true},
+		{"department admin rejects in department", deptAdmin, ActionReject, achievement, true},
+		{"department admin reads student in department", deptAdmin, ActionRead, student, true},
+		{"department admin cannot verify other department", deptAdmin, ActionVerify, otherAchievement, false},
+		{"department admin cannot read other department", deptAdmin, ActionRead, otherStudent, false},
+		{"department admin cannot manage students", deptAdmin, ActionManage, student, false},
+		{"department admin cannot delegate", deptAdmin, ActionDelegate, lecturer, false},
+		{"department permission without unit grants nothing", deptAdminNoUnit, ActionRead, achievement, false},
+
+		// admin unit
+		{"unit admin verifies inside unit", unitAdmin, ActionVerify, achievement, true},
+		{"unit admin manages student inside unit", unitAdmin, ActionManage, student, true},
+		{"unit admin delegates for lecturer inside unit", unitAdmin, ActionDelegate, lecturer, true},
+		{"unit admin cannot read outside unit", unitAdmin, ActionRead, otherAchievement, false},
+		{"unit admin cannot manage outside unit", unitAdmin, ActionManage, otherStudent, false},
+		{"unit admin cannot update student achievement", unitAdmin, ActionUpdate, achievement, false},
+
+		// super admin
+		{"super admin reads any achievement", superAdmin, ActionRead, otherAchievement, true},
+		{"super admin verifies any achievement", superAdmin, ActionVerify, otherAchievement, true},
+		{"super admin manages any student", superAdmin, ActionManage, otherStudent, true},
+		{"super admin delegates for any lecturer", superAdmin, ActionDelegate, lecturer, true},
+		{"super admin cannot submit for student", superAdmin, ActionSubmit, achievement, false},
+		{"super admin cannot delete student achievement", superAdmin, ActionDelete, achievement, false},
+
+		// delegasi
+		{"active delegate verifies", delegate, ActionVerify, achievement, true},
+		{"active delegate reads", delegate, ActionRead, achievement, true},
+		{"active delegate cannot verify other advisor", delegate, ActionVerify, otherAchievement, false},
+		{"expired delegate cannot verify", expiredDelegate, ActionVerify, achievement, false},
+		{"expired delegate cannot read", expiredDelegate, ActionRead, achievement, false},
+		{"not yet started delegate cannot verify", futureDelegate, ActionVerify, achievement, false},
+		{"revoked delegate cannot verify", revokedDelegate, ActionVerify, achievement, false},
+
+		// stage approval chain
+		{"advisor stage: advisor verifies", advisor, ActionVerify, advisorStage, true},
+		{"advisor stage: delegate verifies", delegate, ActionVerify, advisorStage, true},
+		{"department stage: advisor cannot verify", advisor, ActionVerify, deptStage, false},
+		{"department stage: advisor still reads", advisor, ActionRead, deptStage, true},
+		{"department stage: approver in department verifies", deptApprover, ActionVerify, deptStage, true},
+		{"department stage: approver in department reads", deptApprover, ActionRead, deptStage, true},
+		{"department stage: approver in other department cannot verify", otherDeptApprover, ActionVerify, deptStage, false},
+		{"department stage: unit admin without stage permission cannot verify", unitAdmin, ActionVerify, deptStage, false},
+		{"department stage: affairs approver cannot verify", affairsApprover, ActionVerify, deptStage, false},
+		{"global stage: unscoped approver verifies", affairsApprover, ActionVerify, affairsStage, true},
+		{"global stage: scoped approver inside scope rejects", facultyAffairs, ActionReject, affairsStage, true},
+		{"global stage: scoped approver outside scope cannot verify", otherFacAffairs, ActionVerify, affairsStage, false},
+		{"global stage: department approver cannot verify", deptApprover, ActionVerify, affairsStage, false},
+		{"global stage: super admin verifies", superAdmin, ActionVerify, affairsStage, true},
+		{"stage permission without stage grants nothing", affairsApprover, ActionVerify, achievement, false},
+		{"owner cannot approve own stage", owner, ActionVerify, affairsStage, false},
+
+		// kind / action yang tidak dikenal
+		{"unknown action", superAdmin, Action("archive"), achievement, false},
+		{"unknown kind", superAdmin, ActionRead, Resource{Kind: "report"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Can(tt.actor, tt.action, tt.res); got != tt.want {
+				t.Fatalf("Can(%s) = %v, want %v", tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViaDelegation(t *testing.T) {
+	advisorID := uuid.New()
+	res := Resource{Kind: KindAchievement, StudentID: uuid.New(), AdvisorID: advisorID}
+
+	active := models.VerificationDelegation{
+		ID:          uuid.New(),
+		DelegatorID: advisorID,
+		StartsAt:    time.Now().Add(-time.Hour),
+		EndsAt:      time.Now().Add(time.Hour),
+	}
+
+	delegate := Actor{LecturerID: uuid.New(), Delegations: map[uuid.UUID]models.VerificationDelegation{advisorID: active}}
+	if id, ok := ViaDelegation(delegate, res); !ok || id != active.ID {
+		t.Fatalf("ViaDelegation = %v, %v; want %v, true", id, ok, active.ID)
+	}
+
+	// advisor sendiri tidak tercatat sebagai review lewat delegasi
+	advisor := Actor{LecturerID: advisorID, Delegations: delegate.Delegations}
+	if _, ok := ViaDelegation(advisor, res); ok {
+		t.Fatalf("advisor review recorded as delegated")
+	}
+
+	expired := active
+	expired.EndsAt = time.Now().Add(-time.Minute)
+	delegate.Delegations = map[uuid.UUID]models.VerificationDelegation{advisorID: expired}
+	if _, ok := ViaDelegation(delegate, res); ok {
+		t.Fatalf("expired delegation still used")
+	}
+}
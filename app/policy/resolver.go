@@ -0,0 +1,141 @@
+package policy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Resolver mengisi Actor dan Resource dari database supaya service cukup
+// memanggil Can.
+type Resolver struct {
+	students    repository.StudentRepository
+	lecturers   repository.LecturerRepository
+	permissions *middleware.PermissionCache
+	delegations repository.DelegationRepository
+	approvals   repository.ApprovalRepository
+	orgUnits    repository.OrgUnitRepository
+}
+
+func NewResolver(
+	students repository.StudentRepository,
+	lecturers repository.LecturerRepository,
+	permissions *middleware.PermissionCache,
+	delegations repository.DelegationRepository,
+	approvals repository.ApprovalRepository,
+	orgUnits repository.OrgUnitRepository,
+) *Resolver {
+	return &Resolver{students, lecturers, permissions, delegations, approvals, orgUnits}
+}
+
+// Actor membangun actor dari user yang sudah dimuat JWTMiddleware.
+// Untuk API key, permission diambil dari scopes key.
+func (r *Resolver) Actor(c *fiber.Ctx) (Actor, error) {
+	user := c.Locals("user").(models.Users)
+
+	actor := Actor{UserID: user.ID}
+
+	if scopes, ok := c.Locals("apiKeyScopes").([]string); ok {
+		actor.ServiceAccount = true
+		actor.Permissions = make(map[string]bool, len(scopes))
+		for _, s := range scopes {
+			actor.Permissions[s] = true
+		}
+		return actor, nil
+	}
+
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		actor.OrgUnits = scope.UnitIDs
+	}
+
+	perms, err := r.permissions.Get(c.Context(), user.RoleID)
+	if err != nil {
+		return actor, err
+	}
+	actor.Permissions = perms
+
+	if student, err := r.students.FindByUserID(c.Context(), user.ID.String()); err == nil {
+		actor.StudentID = student.ID
+	}
+
+	// departemen actor: unit scope role-nya, atau unit dosen kalau role-nya
+	// tidak di-scope
+	departmentUnit := user.ScopeOrgUnitID
+
+	if lecturer, err := r.lecturers.FindByUserID(c.Context(), user.ID.String()); err == nil {
+		actor.LecturerID = lecturer.ID
+		if departmentUnit == nil {
+			departmentUnit = lecturer.OrgUnitID
+		}
+
+		// delegasi yang sudah lewat masa berlakunya tidak ikut terbaca
+		delegations, err := r.delegations.FindActiveByDelegate(c.Context(), lecturer.ID)
+		if err != nil {
+			return actor, err
+		}
+		if len(delegations) > 0 {
+			actor.Delegations = make(map[uuid.UUID]models.VerificationDelegation, len(delegations))
+			for _, d := range delegations {
+				actor.Delegations[d.DelegatorID] = d
+			}
+		}
+	}
+
+	if departmentUnit != nil {
+		units, err := r.orgUnits.DepartmentOf(c.Context(), *departmentUnit)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return actor, err
+		}
+		actor.DepartmentUnits = units
+	}
+
+	return actor, nil
+}
+
+// Student membangun resource untuk data mahasiswa. Departemen mahasiswa
+// ditentukan dari org unit-nya.
+func (r *Resolver) Student(ctx context.Context, student models.Student) Resource {
+	return Resource{
+		Kind:      KindStudent,
+		StudentID: student.ID,
+		AdvisorID: student.AdvisorID,
+		OrgUnitID: student.OrgUnitID,
+	}
+}
+
+// Lecturer membangun resource untuk data dosen (mis. pemberian delegasi).
+func (r *Resolver) Lecturer(lecturer models.Lecturer) Resource {
+	return Resource{
+		Kind:      KindLecturer,
+		AdvisorID: lecturer.ID,
+		OrgUnitID: lecturer.OrgUnitID,
+	}
+}
+
+// Achievement membangun resource untuk prestasi milik ref.StudentID,
+// termasuk stage approval yang sedang aktif kalau ada.
+func (r *Resolver) Achievement(ctx context.Context, ref models.AchievementRef) (Resource, error) {
+	student, err := r.students.FindByID(ctx, ref.StudentID)
+	if err != nil {
+		return Resource{}, err
+	}
+
+	res := r.Student(ctx, student)
+	res.Kind = KindAchievement
+
+	stage, err := r.approvals.Pending(ctx, ref.ID)
+	if err != nil {
+		return Resource{}, err
+	}
+	if stage != nil {
+		res.Stage = &Stage{Permission: stage.Permission, Scope: stage.Scope}
+	}
+
+	return res, nil
+}
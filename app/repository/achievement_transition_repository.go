@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrStageDecided: stage approval sudah diputuskan request lain lebih dulu.
+var ErrStageDecided = errors.New("approval stage has already been decided, please reload")
+
+// TransitionWrite berisi semua perubahan SQL dari satu transisi workflow.
+// Ref adalah hasil transisi (status baru), From status sebelumnya.
+type TransitionWrite struct {
+	Ref          models.AchievementRef
+	From         string
+	By           uuid.UUID
+	DelegationID *uuid.UUID
+	Note         string
+
+	// ApproveStage: stage approval yang disetujui transisi ini.
+	ApproveStage *uuid.UUID
+	// CloseRound: isi hasil review (Ref.Status) pada round yang terbuka.
+	CloseRound bool
+	// OpenRound: round submit baru beserta stage chain yang berlaku untuknya.
+	OpenRound *models.AchievementSubmission
+	Stages    []models.ApprovalStage
+}
+
+type TransitionRepository interface {
+	// Apply menulis TransitionWrite dalam satu transaksi. Gagal dengan
+	// ErrStatusChanged / ErrStageDecided kalau request lain menang lebih dulu;
+	// tidak ada yang tersimpan kalau salah satu langkah gagal.
+	Apply(ctx context.Context, w TransitionWrite) error
+}
+
+type transitionRepo struct {
+	db *sql.DB
+}
+
+func NewTransitionRepository(db *sql.DB) TransitionRepository {
+	return &transitionRepo{db}
+}
+
+func (r *transitionRepo) Apply(ctx context.Context, w TransitionWrite) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ref := w.Ref
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET status = $3,
+			submitted_at = $4,
+			verified_at = $5,
+			verified_by = $6,
+			delegation_id = $7,
+			rejection_note = $8,
+			revision_note = $9,
+			updated_at = $10
+		WHERE id = $1 AND status = $2
+	`,
+		ref.ID, w.From, ref.Status,
+		ref.SubmittedAt, ref.VerifiedAt, ref.VerifiedBy, ref.DelegationID,
+		ref.RejectionNote, ref.RevisionNote, ref.UpdatedAt,
+	)
+	if err := expectOneRow(res, err, ErrStatusChanged); err != nil {
+		return err
+	}
+
+	if w.ApproveStage != nil {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE achievement_approvals
+			SET approved_by = $2, delegation_id = $3, note = NULLIF($4, ''), approved_at = $5
+			WHERE id = $1 AND approved_at IS NULL
+		`, *w.ApproveStage, w.By, w.DelegationID, w.Note, ref.UpdatedAt)
+		if err := expectOneRow(res, err, ErrStageDecided); err != nil {
+			return err
+		}
+	}
+
+	if w.CloseRound {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE achievement_submissions
+			SET outcome = $2, reviewed_by = $3, delegation_id = $4, note = NULLIF($5, ''), reviewed_at = $6
+			WHERE achievement_id = $1 AND outcome IS NULL
+		`, ref.ID, ref.Status, w.By, w.DelegationID, w.Note, ref.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.OpenRound != nil {
+		if err := openRound(ctx, tx, *w.OpenRound, w.Stages); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// openRound menyimpan round baru (nomor round dihitung dari round terakhir)
+// lalu menyalin stage chain ke round tersebut.
+func openRound(ctx context.Context, tx *sql.Tx, s models.AchievementSubmission, stages []models.ApprovalStage) error {
+	snapshot, err := json.Marshal(s.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	if s.Changes == nil {
+		s.Changes = []models.FieldChange{}
+	}
+	changes, err := json.Marshal(s.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO achievement_submissions (
+			id, achievement_id, round, snapshot, changes, submitted_by, submitted_at
+		)
+		SELECT $1, $2, COALESCE(MAX(round), 0) + 1, $3, $4, $5, $6
+		FROM achievement_submissions
+		WHERE achievement_id = $2
+	`, s.ID, s.AchievementID, snapshot, changes, s.SubmittedBy, s.SubmittedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range stages {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO achievement_approvals (
+				id, submission_id, achievement_id, position, name, permission, scope
+			) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, uuid.New(), s.ID, s.AchievementID, st.Position, st.Name, st.Permission, st.Scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func expectOneRow(res sql.Result, err error, conflict error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return conflict
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"uas/app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AchievementVersionRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Append menyimpan versi berikutnya untuk dokumen hexID.
+	Append(ctx context.Context, hexID string, change string, content models.AchievementSnapshot, authorID string) (models.AchievementVersion, error)
+	FindByAchievement(ctx context.Context, hexID string) ([]models.AchievementVersion, error)
+	FindVersion(ctx context.Context, hexID string, version int) (models.AchievementVersion, error)
+	DeleteByAchievement(ctx context.Context, hexID string) error
+}
+
+type achievementVersionRepo struct {
+	col *mongo.Collection
+}
+
+func NewAchievementVersionRepository(db *mongo.Database) AchievementVersionRepository {
+	return &achievementVersionRepo{
+		col: db.Collection("achievement_versions"),
+	}
+}
+
+func (r *achievementVersionRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "achievementId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *achievementVersionRepo) Append(
+	ctx context.Context,
+	hexID string,
+	change string,
+	content models.AchievementSnapshot,
+	authorID string,
+) (models.AchievementVersion, error) {
+	v := models.AchievementVersion{
+		AchievementID: hexID,
+		Change:        change,
+		Content:       content,
+		AuthorID:      authorID,
+	}
+
+	// nomor versi dijaga unique index; kalau bentrok dengan request lain, coba lagi
+	for attempt := 0; ; attempt++ {
+		last, err := r.latest(ctx, hexID)
+		if err != nil {
+			return v, err
+		}
+
+		v.Version = last + 1
+		v.CreatedAt = time.Now()
+
+		res, err := r.col.InsertOne(ctx, v)
+		if mongo.IsDuplicateKeyError(err) && attempt < 3 {
+			continue
+		}
+		if err != nil {
+			return v, err
+		}
+
+		if id, ok := res.InsertedID.(primitive.ObjectID); ok {
+			v.ID = id
+		}
+		return v, nil
+	}
+}
+
+func (r *achievementVersionRepo) latest(ctx context.Context, hexID string) (int, error) {
+	var last models.AchievementVersion
+
+	err := r.col.FindOne(ctx,
+		bson.M{"achievementId": hexID},
+		options.FindOne().SetSort(bson.M{"version": -1}),
+	).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+
+	return last.Version, err
+}
+
+func (r *achievementVersionRepo) FindByAchievement(ctx context.Context, hexID string) ([]models.AchievementVersion, error) {
+	cursor, err := r.col.Find(ctx,
+		bson.M{"achievementId": hexID},
+		options.Find().SetSort(bson.M{"version": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	list := []models.AchievementVersion{}
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *achievementVersionRepo) FindVersion(ctx context.Context, hexID string, version int) (models.AchievementVersion, error) {
+	var v models.AchievementVersion
+
+	err := r.col.FindOne(ctx, bson.M{"achievementId": hexID, "version": version}).Decode(&v)
+	return v, err
+}
+
+func (r *achievementVersionRepo) DeleteByAchievement(ctx context.Context, hexID string) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"achievementId": hexID})
+	return err
+}
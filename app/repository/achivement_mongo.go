@@ -16,8 +16,10 @@ type MongoAchievementRepository interface {
 	FindByHexIDs(ctx context.Context, ids []string) ([]models.AchievementDetail, error)
 	Insert(ctx context.Context, a models.AchievementDetail) (*mongo.InsertOneResult, error)
 	UpdateByHexID(ctx context.Context, hexID string, update bson.M) error
+	// UpdateAndFindByHexID mengembalikan dokumen sebagaimana tersimpan setelah update.
+	UpdateAndFindByHexID(ctx context.Context, hexID string, update bson.M) (models.AchievementDetail, error)
 	DeleteByHexID(ctx context.Context, hexID string) error
-	PushHistoryByHexID(ctx context.Context, hexID string, status string) error
+	PushHistoryByHexID(ctx context.Context, hexID string, status string, changedBy string, note string) error
 	FindByHexID(ctx context.Context, hexID string) (models.AchievementDetail, error)
 	GetHistory(ctx context.Context, hexID string) ([]bson.M, error)
 }
@@ -77,6 +79,23 @@ func (r *mongoAchievementRepo) UpdateByHexID(ctx context.Context, hexID string,
 	return err
 }
 
+func (r *mongoAchievementRepo) UpdateAndFindByHexID(ctx context.Context, hexID string, update bson.M) (models.AchievementDetail, error) {
+	var detail models.AchievementDetail
+
+	objectId, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return detail, err
+	}
+
+	err = r.col.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectId},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&detail)
+
+	return detail, err
+}
+
 func (r *mongoAchievementRepo) DeleteByHexID(ctx context.Context, hexID string) error {
 	objectId, err := primitive.ObjectIDFromHex(hexID)
 	if err != nil {
@@ -87,19 +106,25 @@ func (r *mongoAchievementRepo) DeleteByHexID(ctx context.Context, hexID string)
 	return err
 }
 
-func (r *mongoAchievementRepo) PushHistoryByHexID(ctx context.Context, hexID string, status string) error {
+// PushHistoryByHexID mencatat perubahan status; note boleh kosong.
+func (r *mongoAchievementRepo) PushHistoryByHexID(ctx context.Context, hexID string, status string, changedBy string, note string) error {
 	objectId, err := primitive.ObjectIDFromHex(hexID)
 	if err != nil {
 		return err
 	}
 
+	entry := bson.M{
+		"status":    status,
+		"timestamp": primitive.NewDateTimeFromTime(time.Now()),
+		"changedBy": changedBy,
+	}
+	if note != "" {
+		entry["note"] = note
+	}
+
 	update := bson.M{
 		"$push": bson.M{
-			"history": bson.M{
-				"status":    status,
-				"timestamp": primitive.NewDateTimeFromTime(time.Now()),
-				"changedBy": "system",
-			},
+			"history": entry,
 		},
 	}
 
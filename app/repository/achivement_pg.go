@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"time"
+	"errors"
+	"strconv"
 	"uas/app/models"
 
 	"github.com/google/uuid"
@@ -16,12 +17,15 @@ type AchievementRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (models.AchievementRef, error)
 	FindByStudent(ctx context.Context, studentID uuid.UUID) ([]models.AchievementRef, error)
 	FindByAdvisor(ctx context.Context, lecturerID uuid.UUID) ([]models.AchievementRef, error)
-	UpdateStatusVerified(ctx context.Context, id uuid.UUID, lecturerID uuid.UUID, now time.Time) error
-	UpdateStatusRejected(ctx context.Context, id uuid.UUID, lecturerID uuid.UUID, note string, now time.Time) error
-	UpdateStatusSubmitted(ctx context.Context, id uuid.UUID, submittedAt time.Time) error
+	// WhileStatus menjalankan fn selama status prestasi masih diterima allowed.
+	// Gagal dengan ErrStatusChanged kalau status sudah tidak diterima.
+	WhileStatus(ctx context.Context, id uuid.UUID, allowed func(status string) bool, fn func() error) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// ErrStatusChanged: status prestasi sudah diubah request lain lebih dulu.
+var ErrStatusChanged = errors.New("achievement status has changed, please reload")
+
 type achievementRepo struct {
 	db *sql.DB
 }
@@ -37,6 +41,9 @@ func (r *achievementRepo) FindAll(
 	offset int,
 ) ([]models.AchievementRef, error) {
 
+	// prestasi ikut unit mahasiswanya; admin unit hanya melihat unit-nya
+	cond, args := scopeFilter(ctx, "s.org_unit_id", []interface{}{limit, offset})
+
 	var (
 		rows *sql.Rows
 		err  error
@@ -44,23 +51,27 @@ func (r *achievementRepo) FindAll(
 
 	if status == "" {
 		query := `
-			SELECT id, student_id, status, mongo_achievement_id, created_at, updated_at
-			FROM achievement_references
-			ORDER BY created_at DESC
+			SELECT ar.id, ar.student_id, ar.status, ar.mongo_achievement_id, ar.created_at, ar.updated_at
+			FROM achievement_references ar
+			JOIN students s ON s.id = ar.student_id
+			WHERE ` + cond + `
+			ORDER BY ar.created_at DESC
 			LIMIT $1 OFFSET $2
 		`
 
-		rows, err = r.db.QueryContext(ctx, query, limit, offset)
+		rows, err = r.db.QueryContext(ctx, query, args...)
 	} else {
+		args = append(args, status)
 		query := `
-			SELECT id, student_id, status, mongo_achievement_id, created_at, updated_at
-			FROM achievement_references
-			WHERE status = $1::achievement_status
-			ORDER BY created_at DESC
-			LIMIT $2 OFFSET $3
+			SELECT ar.id, ar.student_id, ar.status, ar.mongo_achievement_id, ar.created_at, ar.updated_at
+			FROM achievement_references ar
+			JOIN students s ON s.id = ar.student_id
+			WHERE ar.status = $` + strconv.Itoa(len(args)) + `::achievement_status AND ` + cond + `
+			ORDER BY ar.created_at DESC
+			LIMIT $1 OFFSET $2
 		`
 
-		rows, err = r.db.QueryContext(ctx, query, status, limit, offset)
+		rows, err = r.db.QueryContext(ctx, query, args...)
 	}
 
 	if err != nil {
@@ -90,13 +101,15 @@ func (r *achievementRepo) FindAll(
 func (r *achievementRepo) CountAll(ctx context.Context, status string) (int, error) {
 	var total int
 
+	cond, args := scopeFilter(ctx, "s.org_unit_id", []interface{}{status})
+
 	query := `
 		SELECT COUNT(*)
-		FROM achievement_references
-		WHERE ($1 = '' OR status = $1)
-	`
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		WHERE ($1 = '' OR ar.status::text = $1) AND ` + cond
 
-	err := r.db.QueryRowContext(ctx, query, status).Scan(&total)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&total)
 	return total, err
 }
 
@@ -122,8 +135,8 @@ func (r *achievementRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Ac
 	err := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, student_id, mongo_achievement_id, status,
-			submitted_at, verified_at, verified_by,
-			rejection_note, created_at, updated_at
+			submitted_at, verified_at, verified_by, delegation_id,
+			rejection_note, revision_note, created_at, updated_at
 		FROM achievement_references
 		WHERE id = $1
 	`, id).Scan(
@@ -134,7 +147,9 @@ func (r *achievementRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Ac
 		&ref.SubmittedAt,
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
+		&ref.DelegationID,
 		&ref.RejectionNote,
+		&ref.RevisionNote,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
@@ -143,15 +158,18 @@ func (r *achievementRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Ac
 }
 
 func (r *achievementRepo) FindByStudent(ctx context.Context, studentID uuid.UUID) ([]models.AchievementRef, error) {
+	cond, args := scopeFilter(ctx, "s.org_unit_id", []interface{}{studentID})
+
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
-			id, student_id, mongo_achievement_id, status,
-			submitted_at, verified_at, verified_by, rejection_note,
-			created_at, updated_at
-		FROM achievement_references
-		WHERE student_id = $1
-		ORDER BY created_at DESC
-	`, studentID)
+			ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+			ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+			ar.revision_note, ar.created_at, ar.updated_at
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		WHERE ar.student_id = $1 AND `+cond+`
+		ORDER BY ar.created_at DESC
+	`, args...)
 
 	if err != nil {
 		return nil, err
@@ -172,6 +190,7 @@ func (r *achievementRepo) FindByStudent(ctx context.Context, studentID uuid.UUID
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.RevisionNote,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		)
@@ -185,15 +204,17 @@ func (r *achievementRepo) FindByStudent(ctx context.Context, studentID uuid.UUID
 }
 
 func (r *achievementRepo) FindByAdvisor(ctx context.Context, lecturerID uuid.UUID) ([]models.AchievementRef, error) {
+	cond, args := scopeFilter(ctx, "s.org_unit_id", []interface{}{lecturerID})
+
 	rows, err := r.db.QueryContext(ctx, `
         SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
                ar.submitted_at, ar.verified_at, ar.verified_by,
-               ar.rejection_note, ar.created_at, ar.updated_at
+               ar.rejection_note, ar.revision_note, ar.created_at, ar.updated_at
         FROM achievement_references ar
         JOIN students s ON s.id = ar.student_id
-        WHERE s.advisor_id = $1
+        WHERE s.advisor_id = $1 AND `+cond+`
         ORDER BY ar.created_at DESC
-    `, lecturerID)
+    `, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +226,7 @@ func (r *achievementRepo) FindByAdvisor(ctx context.Context, lecturerID uuid.UUI
 		err := rows.Scan(
 			&a.ID, &a.StudentID, &a.MongoAchievementID, &a.Status,
 			&a.SubmittedAt, &a.VerifiedAt, &a.VerifiedBy,
-			&a.RejectionNote, &a.CreatedAt, &a.UpdatedAt,
+			&a.RejectionNote, &a.RevisionNote, &a.CreatedAt, &a.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -215,52 +236,41 @@ func (r *achievementRepo) FindByAdvisor(ctx context.Context, lecturerID uuid.UUI
 	return list, nil
 }
 
-func (r *achievementRepo) UpdateStatusVerified(
+// WhileStatus mengunci row prestasi (FOR UPDATE) sampai fn selesai, jadi
+// transisi workflow seperti submit menunggu perubahan isi yang sedang
+// berjalan dan tidak ada perubahan isi setelah status berpindah.
+func (r *achievementRepo) WhileStatus(
 	ctx context.Context,
 	id uuid.UUID,
-	lecturerID uuid.UUID,
-	now time.Time,
+	allowed func(status string) bool,
+	fn func() error,
 ) error {
-	_, err := r.db.ExecContext(ctx, `
-        UPDATE achievement_references
-        SET status='verified',
-            verified_at=$2,
-            verified_by=$3,
-            updated_at=$2
-        WHERE id=$1
-    `, id, now, lecturerID)
-	return err
-}
-
-func (r *achievementRepo) UpdateStatusRejected(
-	ctx context.Context,
-	id uuid.UUID,
-	lecturerID uuid.UUID,
-	note string,
-	now time.Time,
-) error {
-	_, err := r.db.ExecContext(ctx, `
-        UPDATE achievement_references
-        SET status='rejected',
-            verified_at=$2,
-            verified_by=$3,
-            rejection_note=$4,
-            updated_at=$2
-        WHERE id=$1
-    `, id, now, lecturerID, note)
-	return err
-}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-func (r *achievementRepo) UpdateStatusSubmitted(ctx context.Context, id uuid.UUID, submittedAt time.Time) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE achievement_references
-		SET status = 'submitted',
-			submitted_at = $2,
-			updated_at = NOW()
+	var status string
+	err = tx.QueryRowContext(ctx, `
+		SELECT status
+		FROM achievement_references
 		WHERE id = $1
-	`, id, submittedAt)
+		FOR UPDATE
+	`, id).Scan(&status)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if !allowed(status) {
+		return ErrStatusChanged
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *achievementRepo) Delete(ctx context.Context, id uuid.UUID) error {
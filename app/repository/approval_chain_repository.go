@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type ApprovalChainRepository interface {
+	FindAll(ctx context.Context) ([]models.ApprovalChain, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.ApprovalChain, error)
+	// Match mengembalikan chain paling spesifik untuk level & kategori
+	// prestasi, atau sql.ErrNoRows kalau tidak ada yang cocok.
+	Match(ctx context.Context, level string, category string) (models.ApprovalChain, error)
+	Create(ctx context.Context, chain models.ApprovalChain) error
+	// Update mengganti nama, kriteria dan seluruh stage chain.
+	Update(ctx context.Context, chain models.ApprovalChain) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type approvalChainRepo struct {
+	db *sql.DB
+}
+
+func NewApprovalChainRepository(db *sql.DB) ApprovalChainRepository {
+	return &approvalChainRepo{db}
+}
+
+func (r *approvalChainRepo) FindAll(ctx context.Context) ([]models.ApprovalChain, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, level, category, created_at, updated_at
+		FROM approval_chains
+		ORDER BY level NULLS LAST, category NULLS LAST, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.ApprovalChain
+	for rows.Next() {
+		var ch models.ApprovalChain
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Level, &ch.Category, &ch.CreatedAt, &ch.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, ch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		if list[i].Stages, err = r.stages(ctx, list[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (r *approvalChainRepo) FindByID(ctx context.Context, id uuid.UUID) (models.ApprovalChain, error) {
+	return r.findOne(ctx, `
+		SELECT id, name, level, category, created_at, updated_at
+		FROM approval_chains
+		WHERE id = $1
+	`, id)
+}
+
+func (r *approvalChainRepo) Match(ctx context.Context, level string, category string) (models.ApprovalChain, error) {
+	return r.findOne(ctx, `
+		SELECT id, name, level, category, created_at, updated_at
+		FROM approval_chains
+		WHERE (level IS NULL OR LOWER(level) = LOWER($1))
+		  AND (category IS NULL OR LOWER(category) = LOWER($2))
+		ORDER BY (level IS NOT NULL) DESC, (category IS NOT NULL) DESC
+		LIMIT 1
+	`, level, category)
+}
+
+func (r *approvalChainRepo) findOne(ctx context.Context, query string, args ...interface{}) (models.ApprovalChain, error) {
+	var ch models.ApprovalChain
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&ch.ID, &ch.Name, &ch.Level, &ch.Category, &ch.CreatedAt, &ch.UpdatedAt,
+	)
+	if err != nil {
+		return ch, err
+	}
+
+	ch.Stages, err = r.stages(ctx, ch.ID)
+	return ch, err
+}
+
+func (r *approvalChainRepo) stages(ctx context.Context, chainID uuid.UUID) ([]models.ApprovalStage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, position, name, permission, scope
+		FROM approval_stages
+		WHERE chain_id = $1
+		ORDER BY position
+	`, chainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stages := []models.ApprovalStage{}
+	for rows.Next() {
+		var st models.ApprovalStage
+		if err := rows.Scan(&st.ID, &st.Position, &st.Name, &st.Permission, &st.Scope); err != nil {
+			return nil, err
+		}
+		stages = append(stages, st)
+	}
+	return stages, rows.Err()
+}
+
+func (r *approvalChainRepo) Create(ctx context.Context, ch models.ApprovalChain) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO approval_chains (id, name, level, category)
+		VALUES ($1, $2, $3, $4)
+	`, ch.ID, ch.Name, ch.Level, ch.Category)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+
+	if err := insertStages(ctx, tx, ch); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *approvalChainRepo) Update(ctx context.Context, ch models.ApprovalChain) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE approval_chains
+		SET name = $2, level = $3, category = $4, updated_at = NOW()
+		WHERE id = $1
+	`, ch.ID, ch.Name, ch.Level, ch.Category)
+	if err != nil {
+		return mapUniqueViolation(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM approval_stages WHERE chain_id = $1`, ch.ID); err != nil {
+		return err
+	}
+
+	if err := insertStages(ctx, tx, ch); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertStages(ctx context.Context, tx *sql.Tx, ch models.ApprovalChain) error {
+	for _, st := range ch.Stages {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO approval_stages (id, chain_id, position, name, permission, scope)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, st.ID, ch.ID, st.Position, st.Name, st.Permission, st.Scope)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete tidak memengaruhi prestasi yang sedang berjalan karena stage-nya
+// sudah disalin ke achievement_approvals saat submit.
+func (r *approvalChainRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM approval_chains WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ApprovalRepository interface {
+	// Pending mengembalikan stage yang sedang menunggu persetujuan pada
+	// round yang masih terbuka, atau nil kalau prestasi tidak memakai chain.
+	Pending(ctx context.Context, achievementID uuid.UUID) (*models.AchievementApproval, error)
+	FindBySubmission(ctx context.Context, submissionID uuid.UUID) ([]models.AchievementApproval, error)
+	// FindQueue mengembalikan prestasi yang stage aktifnya membutuhkan salah
+	// satu permission tersebut (nil = semua), dibatasi scope unit di ctx.
+	FindQueue(ctx context.Context, permissions []string, stage string) ([]models.ApprovalQueueItem, error)
+}
+
+type approvalRepo struct {
+	db *sql.DB
+}
+
+func NewApprovalRepository(db *sql.DB) ApprovalRepository {
+	return &approvalRepo{db}
+}
+
+const approvalColumns = `
+	a.id, a.submission_id, a.achievement_id, a.position, a.name, a.permission, a.scope,
+	a.position = (SELECT MAX(position) FROM achievement_approvals WHERE submission_id = a.submission_id),
+	a.approved_by, a.approved_at, a.delegation_id, a.note
+`
+
+func approvalFields(a *models.AchievementApproval) []interface{} {
+	return []interface{}{
+		&a.ID, &a.SubmissionID, &a.AchievementID, &a.Position, &a.Name, &a.Permission, &a.Scope,
+		&a.Final, &a.ApprovedBy, &a.ApprovedAt, &a.DelegationID, &a.Note,
+	}
+}
+
+func (r *approvalRepo) Pending(ctx context.Context, achievementID uuid.UUID) (*models.AchievementApproval, error) {
+	var a models.AchievementApproval
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM achievement_approvals a
+		JOIN achievement_submissions sub ON sub.id = a.submission_id
+		WHERE a.achievement_id = $1 AND sub.outcome IS NULL AND a.approved_at IS NULL
+		ORDER BY a.position
+		LIMIT 1
+	`, achievementID).Scan(approvalFields(&a)...)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *approvalRepo) FindBySubmission(ctx context.Context, submissionID uuid.UUID) ([]models.AchievementApproval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM achievement_approvals a
+		WHERE a.submission_id = $1
+		ORDER BY a.position
+	`, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AchievementApproval{}
+	for rows.Next() {
+		var a models.AchievementApproval
+		if err := rows.Scan(approvalFields(&a)...); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (r *approvalRepo) FindQueue(ctx context.Context, permissions []string, stage string) ([]models.ApprovalQueueItem, error) {
+	var perms interface{}
+	if permissions != nil {
+		perms = pq.Array(permissions)
+	}
+
+	cond, args := scopeFilter(ctx, "s.org_unit_id", []interface{}{perms, stage})
+
+	// stage aktif = stage pertama yang belum disetujui pada round terbuka
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+			ar.submitted_at, ar.created_at, ar.updated_at,
+			`+approvalColumns+`
+		FROM (
+			SELECT DISTINCT ON (x.achievement_id) x.*
+			FROM achievement_approvals x
+			JOIN achievement_submissions sub ON sub.id = x.submission_id
+			WHERE sub.outcome IS NULL AND x.approved_at IS NULL
+			ORDER BY x.achievement_id, x.position
+		) a
+		JOIN achievement_references ar ON ar.id = a.achievement_id
+		JOIN students s ON s.id = ar.student_id
+		WHERE ($1::text[] IS NULL OR a.permission = ANY($1::text[]))
+		  AND ($2 = '' OR LOWER(a.name) = LOWER($2))
+		  AND `+cond+`
+		ORDER BY ar.submitted_at
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.ApprovalQueueItem{}
+	for rows.Next() {
+		var item models.ApprovalQueueItem
+		ref := &item.Achievement
+
+		fields := append([]interface{}{
+			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status,
+			&ref.SubmittedAt, &ref.CreatedAt, &ref.UpdatedAt,
+		}, approvalFields(&item.Stage)...)
+
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
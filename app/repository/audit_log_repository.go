@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogRepository interface {
+	Record(ctx context.Context, l models.AuditLog) error
+	// FindByUser mengembalikan log di mana user adalah subject atau impersonator.
+	FindByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.AuditLog, error)
+}
+
+type auditLogRepo struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepo{db}
+}
+
+func (r *auditLogRepo) Record(ctx context.Context, l models.AuditLog) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO audit_logs (id, user_id, impersonator_id, method, path, status, ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	`, l.ID, l.UserID, l.ImpersonatorID, l.Method, l.Path, l.Status, l.IP)
+	return err
+}
+
+func (r *auditLogRepo) FindByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.AuditLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, impersonator_id, method, path, status, COALESCE(ip, ''), created_at
+		FROM audit_logs
+		WHERE user_id = $1 OR impersonator_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AuditLog
+	for rows.Next() {
+		var l models.AuditLog
+		if err := rows.Scan(
+			&l.ID, &l.UserID, &l.ImpersonatorID, &l.Method, &l.Path, &l.Status, &l.IP, &l.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, nil
+}
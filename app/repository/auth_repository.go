@@ -4,12 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (models.Users, error)
 	FindByID(ctx context.Context, id string) (models.Users, error)
+	// FindForToken mengembalikan user beserta status revoked salah satu jti.
+	FindForToken(ctx context.Context, id string, jtis ...string) (models.Users, bool, error)
+	FindByEmail(ctx context.Context, email string) (models.Users, error)
+	UpdatePassword(ctx context.Context, id string, passwordHash string) error
+	ReplacePasswordHash(ctx context.Context, id string, oldHash string, newHash string) error
+	RegisterLoginFailure(ctx context.Context, id string) (int, error)
+	ResetLoginFailures(ctx context.Context, id string) error
+	Lock(ctx context.Context, id string, until time.Time) error
+	UpsertFromDirectory(ctx context.Context, u models.Users, i models.UserIdentity) (models.Users, error)
 }
 
 type userRepository struct {
@@ -32,7 +45,12 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (m
 			password_hash,
 			full_name,
 			role_id,
-			is_active
+			is_active,
+			failed_login_count,
+			last_failed_login_at,
+			locked_until,
+			token_version,
+			scope_org_unit_id
 		FROM users
 		WHERE username = $1
 		LIMIT 1
@@ -46,6 +64,11 @@ func (r *userRepository) FindByUsername(ctx context.Context, username string) (m
 		&u.FullName,
 		&u.RoleID,
 		&u.IsActive,
+		&u.FailedLoginCount,
+		&u.LastFailedLoginAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
+		&u.ScopeOrgUnitID,
 	)
 
 	if err != nil {
@@ -67,7 +90,12 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (models.Users,
 			password_hash,
 			full_name,
 			role_id,
-			is_active
+			is_active,
+			failed_login_count,
+			last_failed_login_at,
+			locked_until,
+			token_version,
+			scope_org_unit_id
 		FROM users
 		WHERE id = $1
 		LIMIT 1
@@ -81,6 +109,102 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (models.Users,
 		&u.FullName,
 		&u.RoleID,
 		&u.IsActive,
+		&u.FailedLoginCount,
+		&u.LastFailedLoginAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
+		&u.ScopeOrgUnitID,
+	)
+
+	if err != nil {
+		return u, errors.New("user not found")
+	}
+
+	return u, nil
+}
+
+// FindForToken dipakai JWTMiddleware di setiap request: status akun,
+// token_version dan scope selalu dibaca langsung dari database, sekaligus
+// mengecek apakah salah satu jti (token / session) sudah dicabut.
+func (r *userRepository) FindForToken(ctx context.Context, id string, jtis ...string) (models.Users, bool, error) {
+	var u models.Users
+	var revoked bool
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			id,
+			username,
+			email,
+			password_hash,
+			full_name,
+			role_id,
+			is_active,
+			failed_login_count,
+			last_failed_login_at,
+			locked_until,
+			token_version,
+			scope_org_unit_id,
+			EXISTS (
+				SELECT 1 FROM revoked_tokens
+				WHERE jti = ANY($2) AND expires_at > NOW()
+			)
+		FROM users
+		WHERE id = $1
+	`, id, pq.Array(jtis)).Scan(
+		&u.ID,
+		&u.Username,
+		&u.Email,
+		&u.PasswordHash,
+		&u.FullName,
+		&u.RoleID,
+		&u.IsActive,
+		&u.FailedLoginCount,
+		&u.LastFailedLoginAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
+		&u.ScopeOrgUnitID,
+		&revoked,
+	)
+
+	return u, revoked, err
+}
+
+// find user by email
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (models.Users, error) {
+	var u models.Users
+
+	query := `
+		SELECT 
+			id,
+			username,
+			email,
+			password_hash,
+			full_name,
+			role_id,
+			is_active,
+			failed_login_count,
+			last_failed_login_at,
+			locked_until,
+			token_version,
+			scope_org_unit_id
+		FROM users
+		WHERE LOWER(email) = LOWER($1)
+		LIMIT 1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&u.ID,
+		&u.Username,
+		&u.Email,
+		&u.PasswordHash,
+		&u.FullName,
+		&u.RoleID,
+		&u.IsActive,
+		&u.FailedLoginCount,
+		&u.LastFailedLoginAt,
+		&u.LockedUntil,
+		&u.TokenVersion,
+		&u.ScopeOrgUnitID,
 	)
 
 	if err != nil {
@@ -89,3 +213,149 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (models.Users,
 
 	return u, nil
 }
+
+// UpdatePassword ikut menaikkan token_version sehingga token lama langsung ditolak.
+func (r *userRepository) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET password_hash = $2, token_version = token_version + 1
+		WHERE id = $1
+	`, id, passwordHash)
+	return err
+}
+
+// ReplacePasswordHash dipakai untuk rehash setelah login (password sama,
+// algoritma/parameter baru). Hanya berhasil kalau hash belum diganti request lain.
+func (r *userRepository) ReplacePasswordHash(ctx context.Context, id string, oldHash string, newHash string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET password_hash = $3
+		WHERE id = $1 AND password_hash = $2
+	`, id, oldHash, newHash)
+	return err
+}
+
+// RegisterLoginFailure menaikkan counter gagal login dan mengembalikan nilai barunya.
+// Kunci yang sudah kadaluarsa dihapus dan counter mulai lagi dari 1, supaya
+// satu salah ketik setelah masa kunci tidak langsung mengunci akun lagi.
+func (r *userRepository) RegisterLoginFailure(ctx context.Context, id string) (int, error) {
+	var count int
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE users
+		SET failed_login_count = CASE
+				WHEN locked_until IS NOT NULL AND locked_until < NOW() THEN 1
+				ELSE failed_login_count + 1
+			END,
+			locked_until = CASE
+				WHEN locked_until < NOW() THEN NULL
+				ELSE locked_until
+			END,
+			last_failed_login_at = NOW()
+		WHERE id = $1
+		RETURNING failed_login_count
+	`, id).Scan(&count)
+
+	return count, err
+}
+
+func (r *userRepository) ResetLoginFailures(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *userRepository) Lock(ctx context.Context, id string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET locked_until = $2
+		WHERE id = $1
+	`, id, until)
+	return err
+}
+
+// ErrLocalAccountExists: username directory sudah dipakai akun lokal yang
+// belum pernah di-link; akun itu tidak boleh diambil alih lewat directory.
+var ErrLocalAccountExists = errors.New("username belongs to a local account")
+
+// UpsertFromDirectory menyinkronkan user dari directory eksternal (LDAP).
+// User dicocokkan lewat user_identities (provider + subject), bukan username.
+// RoleID kosong = role user lama dipertahankan. Password hash user yang sudah
+// ada tidak diubah.
+func (r *userRepository) UpsertFromDirectory(ctx context.Context, u models.Users, i models.UserIdentity) (models.Users, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return u, err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT user_id
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+		FOR UPDATE
+	`, i.Provider, i.Subject).Scan(&userID)
+
+	switch {
+	case err == nil:
+		var roleID *uuid.UUID
+		if u.RoleID != uuid.Nil {
+			roleID = &u.RoleID
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users
+			SET email = $2,
+				full_name = $3,
+				role_id = COALESCE($4::uuid, role_id),
+				token_version = token_version +
+					CASE WHEN $4::uuid IS NOT NULL AND role_id <> $4::uuid THEN 1 ELSE 0 END
+			WHERE id = $1
+		`, userID, u.Email, u.FullName, roleID)
+		if err != nil {
+			return u, err
+		}
+
+	case errors.Is(err, sql.ErrNoRows):
+		var exists bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM users WHERE username = $1)
+		`, u.Username).Scan(&exists)
+		if err != nil {
+			return u, err
+		}
+		if exists {
+			return u, ErrLocalAccountExists
+		}
+		if u.RoleID == uuid.Nil {
+			return u, errors.New("no role mapped for new directory user " + u.Username)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO users (id, username, email, password_hash, full_name, role_id)
+			VALUES ($1,$2,$3,$4,$5,$6)
+		`, u.ID, u.Username, u.Email, u.PasswordHash, u.FullName, u.RoleID)
+		if err != nil {
+			return u, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+		`, i.ID, u.ID, i.Provider, i.Subject, i.Email)
+		if err != nil {
+			return u, err
+		}
+		userID = u.ID.String()
+
+	default:
+		return u, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return u, err
+	}
+
+	return r.FindByID(ctx, userID)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment models.AchievementComment) error
+	FindByID(ctx context.Context, id uuid.UUID) (models.AchievementComment, error)
+	// FindByAchievement mengembalikan semua komentar (urut waktu, belum
+	// disusun jadi thread). Komentar internal hanya ikut kalau includeInternal.
+	FindByAchievement(ctx context.Context, achievementID uuid.UUID, includeInternal bool) ([]models.AchievementComment, error)
+	// Update dan Delete menyimpan isi lama ke achievement_comment_revisions.
+	Update(ctx context.Context, id uuid.UUID, body string, by uuid.UUID, at time.Time) error
+	Delete(ctx context.Context, id uuid.UUID, by uuid.UUID, at time.Time) error
+	FindRevisions(ctx context.Context, commentID uuid.UUID) ([]models.CommentRevision, error)
+}
+
+type commentRepo struct {
+	db *sql.DB
+}
+
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &commentRepo{db}
+}
+
+const commentColumns = `
+	c.id, c.achievement_id, c.parent_id, c.author_id, u.full_name, c.body,
+	c.internal, c.field, c.attachment,
+	EXISTS (SELECT 1 FROM achievement_comment_revisions r WHERE r.comment_id = c.id AND r.action = 'edited'),
+	c.created_at, c.updated_at, c.deleted_at
+`
+
+func scanComment(row interface{ Scan(...interface{}) error }) (models.AchievementComment, error) {
+	var cm models.AchievementComment
+	err := row.Scan(
+		&cm.ID, &cm.AchievementID, &cm.ParentID, &cm.AuthorID, &cm.AuthorName, &cm.Body,
+		&cm.Internal, &cm.Field, &cm.Attachment, &cm.Edited,
+		&cm.CreatedAt, &cm.UpdatedAt, &cm.DeletedAt,
+	)
+	return cm, err
+}
+
+func (r *commentRepo) Create(ctx context.Context, cm models.AchievementComment) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO achievement_comments (
+			id, achievement_id, parent_id, author_id, body, internal, field, attachment,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	`, cm.ID, cm.AchievementID, cm.ParentID, cm.AuthorID, cm.Body, cm.Internal,
+		cm.Field, cm.Attachment, cm.CreatedAt)
+	return err
+}
+
+func (r *commentRepo) FindByID(ctx context.Context, id uuid.UUID) (models.AchievementComment, error) {
+	return scanComment(r.db.QueryRowContext(ctx, `
+		SELECT `+commentColumns+`
+		FROM achievement_comments c
+		JOIN users u ON u.id = c.author_id
+		WHERE c.id = $1
+	`, id))
+}
+
+func (r *commentRepo) FindByAchievement(ctx context.Context, achievementID uuid.UUID, includeInternal bool) ([]models.AchievementComment, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+commentColumns+`
+		FROM achievement_comments c
+		JOIN users u ON u.id = c.author_id
+		WHERE c.achievement_id = $1 AND ($2 OR NOT c.internal)
+		ORDER BY c.created_at
+	`, achievementID, includeInternal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AchievementComment
+	for rows.Next() {
+		cm, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, cm)
+	}
+	return list, rows.Err()
+}
+
+func (r *commentRepo) Update(ctx context.Context, id uuid.UUID, body string, by uuid.UUID, at time.Time) error {
+	return r.revise(ctx, id, models.CommentEdited, by, at, `
+		UPDATE achievement_comments SET body = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, body, at)
+}
+
+func (r *commentRepo) Delete(ctx context.Context, id uuid.UUID, by uuid.UUID, at time.Time) error {
+	return r.revise(ctx, id, models.CommentDeleted, by, at, `
+		UPDATE achievement_comments SET body = '', deleted_at = $2, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, at)
+}
+
+// revise menyimpan isi komentar saat ini sebagai revisi lalu menjalankan
+// query perubahan dalam satu transaksi. sql.ErrNoRows kalau komentar sudah
+// dihapus.
+func (r *commentRepo) revise(
+	ctx context.Context,
+	id uuid.UUID,
+	action string,
+	by uuid.UUID,
+	at time.Time,
+	query string,
+	args ...interface{},
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// row dikunci dulu supaya edit / hapus yang bersamaan menyimpan isi
+	// lamanya satu per satu dan tidak ada isi di antaranya yang hilang
+	var body string
+	err = tx.QueryRowContext(ctx, `
+		SELECT body
+		FROM achievement_comments
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&body)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO achievement_comment_revisions (id, comment_id, action, body, changed_by, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, uuid.New(), id, action, body, by, at)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *commentRepo) FindRevisions(ctx context.Context, commentID uuid.UUID) ([]models.CommentRevision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, comment_id, action, body, changed_by, changed_at
+		FROM achievement_comment_revisions
+		WHERE comment_id = $1
+		ORDER BY changed_at
+	`, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.CommentRevision{}
+	for rows.Next() {
+		var rev models.CommentRevision
+		if err := rows.Scan(&rev.ID, &rev.CommentID, &rev.Action, &rev.Body, &rev.ChangedBy, &rev.ChangedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, rev)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type DelegationRepository interface {
+	Create(ctx context.Context, d models.VerificationDelegation) error
+	FindByID(ctx context.Context, id uuid.UUID) (models.VerificationDelegation, error)
+	// FindByLecturer mengembalikan delegasi yang diberikan maupun diterima dosen.
+	FindByLecturer(ctx context.Context, lecturerID uuid.UUID) ([]models.VerificationDelegation, error)
+	// FindActiveByDelegate hanya mengembalikan delegasi yang sedang berlaku,
+	// jadi delegasi yang lewat ends_at otomatis tidak terpakai lagi.
+	FindActiveByDelegate(ctx context.Context, delegateID uuid.UUID) ([]models.VerificationDelegation, error)
+	Revoke(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+type delegationRepo struct {
+	db *sql.DB
+}
+
+func NewDelegationRepository(db *sql.DB) DelegationRepository {
+	return &delegationRepo{db}
+}
+
+const delegationColumns = `
+	id, delegator_id, delegate_id, starts_at, ends_at,
+	COALESCE(reason, ''), created_by, revoked_at, created_at
+`
+
+func scanDelegation(row interface{ Scan(...interface{}) error }) (models.VerificationDelegation, error) {
+	var d models.VerificationDelegation
+	err := row.Scan(
+		&d.ID, &d.DelegatorID, &d.DelegateID, &d.StartsAt, &d.EndsAt,
+		&d.Reason, &d.CreatedBy, &d.RevokedAt, &d.CreatedAt,
+	)
+	return d, err
+}
+
+func (r *delegationRepo) Create(ctx context.Context, d models.VerificationDelegation) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO verification_delegations (
+			id, delegator_id, delegate_id, starts_at, ends_at, reason, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, d.ID, d.DelegatorID, d.DelegateID, d.StartsAt, d.EndsAt, d.Reason, d.CreatedBy)
+	return err
+}
+
+func (r *delegationRepo) FindByID(ctx context.Context, id uuid.UUID) (models.VerificationDelegation, error) {
+	return scanDelegation(r.db.QueryRowContext(ctx, `
+		SELECT `+delegationColumns+`
+		FROM verification_delegations
+		WHERE id = $1
+	`, id))
+}
+
+func (r *delegationRepo) FindByLecturer(ctx context.Context, lecturerID uuid.UUID) ([]models.VerificationDelegation, error) {
+	return r.query(ctx, `
+		SELECT `+delegationColumns+`
+		FROM verification_delegations
+		WHERE delegator_id = $1 OR delegate_id = $1
+		ORDER BY starts_at DESC
+	`, lecturerID)
+}
+
+func (r *delegationRepo) FindActiveByDelegate(ctx context.Context, delegateID uuid.UUID) ([]models.VerificationDelegation, error) {
+	return r.query(ctx, `
+		SELECT `+delegationColumns+`
+		FROM verification_delegations
+		WHERE delegate_id = $1
+		  AND revoked_at IS NULL
+		  AND starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY ends_at
+	`, delegateID)
+}
+
+func (r *delegationRepo) Revoke(ctx context.Context, id uuid.UUID, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE verification_delegations
+		SET revoked_at = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, now)
+	return err
+}
+
+func (r *delegationRepo) query(ctx context.Context, query string, args ...interface{}) ([]models.VerificationDelegation, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.VerificationDelegation
+	for rows.Next() {
+		d, err := scanDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicate dikembalikan kalau insert/update melanggar unique constraint.
+var ErrDuplicate = errors.New("already exists")
+
+func mapUniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicate
+	}
+	return err
+}
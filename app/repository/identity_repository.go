@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type IdentityRepository interface {
+	FindUserID(ctx context.Context, provider string, subject string) (string, error)
+	Link(ctx context.Context, identity models.UserIdentity) error
+	ProvisionUser(ctx context.Context, user models.Users, identity models.UserIdentity) error
+}
+
+type identityRepo struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepo{db}
+}
+
+func (r *identityRepo) FindUserID(ctx context.Context, provider string, subject string) (string, error) {
+	var userID string
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`, provider, subject).Scan(&userID)
+
+	return userID, err
+}
+
+func (r *identityRepo) Link(ctx context.Context, i models.UserIdentity) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, i.ID, i.UserID, i.Provider, i.Subject, i.Email)
+	return err
+}
+
+// ProvisionUser membuat user baru beserta identity-nya dalam satu transaksi.
+func (r *identityRepo) ProvisionUser(ctx context.Context, u models.Users, i models.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, u.ID, u.Username, u.Email, u.PasswordHash, u.FullName, u.RoleID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, i.ID, u.ID, i.Provider, i.Subject, i.Email)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -11,6 +11,7 @@ import (
 type LecturerRepository interface {
 	Create(ctx context.Context, l models.Lecturer) error
 	FindByUserID(ctx context.Context, userID string) (models.Lecturer, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.Lecturer, error)
 	FindAll(ctx context.Context) ([]models.Lecturer, error)
 	FindAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error)
 }
@@ -26,8 +27,8 @@ func NewLecturerRepository(db *sql.DB) LecturerRepository {
 func (r *lecturerRepo) Create(ctx context.Context, l models.Lecturer) error {
 	query := `
 		INSERT INTO lecturers (
-			id, user_id, lecturer_id, department
-		) VALUES ($1, $2, $3, $4)
+			id, user_id, lecturer_id, department, org_unit_id
+		) VALUES ($1, $2, $3, $4, $5)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -35,6 +36,7 @@ func (r *lecturerRepo) Create(ctx context.Context, l models.Lecturer) error {
 		l.UserID,
 		l.LecturerID,
 		l.Department,
+		l.OrgUnitID,
 	)
 
 	return err
@@ -44,24 +46,40 @@ func (r *lecturerRepo) FindByUserID(ctx context.Context, userID string) (models.
 	var l models.Lecturer
 
 	query := `
-        SELECT id, user_id, lecturer_id, department
+        SELECT id, user_id, lecturer_id, department, org_unit_id
         FROM lecturers
         WHERE user_id = $1
         LIMIT 1
     `
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&l.ID, &l.UserID, &l.LecturerID, &l.Department,
+		&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.OrgUnitID,
+	)
+
+	return l, err
+}
+
+func (r *lecturerRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Lecturer, error) {
+	var l models.Lecturer
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, lecturer_id, department, org_unit_id
+		FROM lecturers
+		WHERE id = $1
+	`, id).Scan(
+		&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.OrgUnitID,
 	)
 
 	return l, err
 }
 
 func (r *lecturerRepo) FindAll(ctx context.Context) ([]models.Lecturer, error) {
+	cond, args := scopeFilter(ctx, "org_unit_id", nil)
+
 	rows, err := r.db.QueryContext(ctx, `
-	SELECT id, user_id, lecturer_id, department
-FROM lecturers
-`)
+		SELECT id, user_id, lecturer_id, department, org_unit_id
+		FROM lecturers
+		WHERE `+cond, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +89,7 @@ FROM lecturers
 	for rows.Next() {
 		var l models.Lecturer
 		if err := rows.Scan(
-			&l.ID, &l.UserID, &l.LecturerID, &l.Department,
+			&l.ID, &l.UserID, &l.LecturerID, &l.Department, &l.OrgUnitID,
 		); err != nil {
 			return nil, err
 		}
@@ -82,11 +100,12 @@ FROM lecturers
 
 func (r *lecturerRepo) FindAdvisees(ctx context.Context, lecturerID uuid.UUID,
 ) ([]models.Student, error) {
+	cond, args := scopeFilter(ctx, "org_unit_id", []interface{}{lecturerID})
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, user_id, student_id, program_study, academic_year, advisor_id
+		SELECT id, user_id, student_id, program_study, academic_year, advisor_id, org_unit_id
 		FROM students
-		WHERE advisor_id = $1
-	`, lecturerID)
+		WHERE advisor_id = $1 AND `+cond, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +116,7 @@ func (r *lecturerRepo) FindAdvisees(ctx context.Context, lecturerID uuid.UUID,
 		var s models.Student
 		if err := rows.Scan(
 			&s.ID, &s.UserID, &s.StudentID,
-			&s.ProgramStudy, &s.AcademicYear, &s.AdvisorID,
+			&s.ProgramStudy, &s.AcademicYear, &s.AdvisorID, &s.OrgUnitID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	Record(ctx context.Context, username string, ip string, success bool) error
+	CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int, error)
+}
+
+type loginAttemptRepo struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepo{db}
+}
+
+func (r *loginAttemptRepo) Record(ctx context.Context, username string, ip string, success bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_attempts (username, ip, success, created_at)
+		VALUES ($1, $2, $3, NOW())
+	`, username, ip, success)
+	return err
+}
+
+func (r *loginAttemptRepo) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	var total int
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM login_attempts
+		WHERE ip = $1 AND success = false AND created_at > $2
+	`, ip, since).Scan(&total)
+
+	return total, err
+}
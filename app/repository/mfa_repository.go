@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	FindByUser(ctx context.Context, userID uuid.UUID) (models.UserMFA, error)
+	SavePending(ctx context.Context, userID uuid.UUID, secret string) (bool, error)
+	Enable(ctx context.Context, userID uuid.UUID) error
+	Disable(ctx context.Context, userID uuid.UUID) error
+	MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type mfaRepo struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepo{db}
+}
+
+func (r *mfaRepo) FindByUser(ctx context.Context, userID uuid.UUID) (models.UserMFA, error) {
+	var m models.UserMFA
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, secret, enabled, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`, userID).Scan(
+		&m.UserID, &m.Secret, &m.Enabled, &m.EnabledAt, &m.LastUsedStep, &m.CreatedAt,
+	)
+
+	return m, err
+}
+
+// SavePending menyimpan secret baru selama MFA belum aktif.
+// Mengembalikan false kalau MFA user sudah aktif.
+func (r *mfaRepo) SavePending(ctx context.Context, userID uuid.UUID, secret string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret, enabled, created_at)
+		VALUES ($1, $2, false, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+		WHERE user_mfa.enabled = false
+	`, userID, secret)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *mfaRepo) Enable(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa SET enabled = true, enabled_at = NOW()
+		WHERE user_id = $1
+	`, userID)
+	return err
+}
+
+func (r *mfaRepo) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkStepUsed menolak kode TOTP yang sama dipakai dua kali.
+func (r *mfaRepo) MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE user_mfa SET last_used_step = $2
+		WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *mfaRepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, NOW())
+		`, uuid.New(), userID, h); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mfaRepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
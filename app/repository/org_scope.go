@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// OrgScope adalah cakupan data admin yang sedang login: unit yang
+// di-assign ke role-nya (RootID) beserta seluruh unit turunannya.
+type OrgScope struct {
+	RootID  uuid.UUID
+	UnitIDs []uuid.UUID
+}
+
+type orgScopeKey struct{}
+
+// OrgScopeKey dipakai JWTMiddleware untuk menaruh OrgScope di c.Locals.
+// c.Context() (fasthttp.RequestCtx) meneruskan Locals lewat ctx.Value,
+// jadi repository cukup membaca ctx yang diterimanya.
+var OrgScopeKey = orgScopeKey{}
+
+func WithOrgScope(ctx context.Context, scope OrgScope) context.Context {
+	return context.WithValue(ctx, OrgScopeKey, scope)
+}
+
+// OrgScopeFromContext mengembalikan ok=false kalau request tidak dibatasi
+// unit (admin pusat, mahasiswa, dosen, service account).
+func OrgScopeFromContext(ctx context.Context) (OrgScope, bool) {
+	scope, ok := ctx.Value(OrgScopeKey).(OrgScope)
+	return scope, ok
+}
+
+func (s OrgScope) Contains(unitID *uuid.UUID) bool {
+	if unitID == nil {
+		return false
+	}
+	for _, id := range s.UnitIDs {
+		if id == *unitID {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeFilter menambahkan kondisi "<column> = ANY($n)" sesuai scope di ctx.
+// Tanpa scope kondisinya TRUE. Baris dengan unit NULL tidak pernah masuk
+// ke scope admin unit.
+func scopeFilter(ctx context.Context, column string, args []interface{}) (string, []interface{}) {
+	scope, ok := OrgScopeFromContext(ctx)
+	if !ok {
+		return "TRUE", args
+	}
+
+	ids := make([]string, len(scope.UnitIDs))
+	for i, id := range scope.UnitIDs {
+		ids[i] = id.String()
+	}
+
+	args = append(args, pq.Array(ids))
+	return fmt.Sprintf("%s = ANY($%d::uuid[])", column, len(args)), args
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrOrgUnitInUse dikembalikan Delete kalau unit masih punya sub-unit,
+// mahasiswa, dosen, atau admin yang di-scope ke unit tersebut.
+var ErrOrgUnitInUse = errors.New("org unit still has sub-units or members")
+
+type OrgUnitRepository interface {
+	FindAll(ctx context.Context) ([]models.OrgUnit, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.OrgUnit, error)
+	Descendants(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	DepartmentOf(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	Create(ctx context.Context, unit models.OrgUnit) error
+	Update(ctx context.Context, unit models.OrgUnit) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	AssignStudent(ctx context.Context, unitID uuid.UUID, studentID uuid.UUID) error
+	AssignLecturer(ctx context.Context, unitID uuid.UUID, lecturerID uuid.UUID) error
+}
+
+type orgUnitRepo struct {
+	db *sql.DB
+}
+
+func NewOrgUnitRepository(db *sql.DB) OrgUnitRepository {
+	return &orgUnitRepo{db}
+}
+
+// FindAll hanya mengembalikan unit di dalam scope caller.
+func (r *orgUnitRepo) FindAll(ctx context.Context) ([]models.OrgUnit, error) {
+	cond, args := scopeFilter(ctx, "id", nil)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, parent_id, name, type, created_at
+		FROM org_units
+		WHERE `+cond+`
+		ORDER BY type, name
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.OrgUnit
+	for rows.Next() {
+		var u models.OrgUnit
+		if err := rows.Scan(&u.ID, &u.ParentID, &u.Name, &u.Type, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, nil
+}
+
+func (r *orgUnitRepo) FindByID(ctx context.Context, id uuid.UUID) (models.OrgUnit, error) {
+	var u models.OrgUnit
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, parent_id, name, type, created_at
+		FROM org_units
+		WHERE id = $1
+	`, id).Scan(&u.ID, &u.ParentID, &u.Name, &u.Type, &u.CreatedAt)
+
+	return u, err
+}
+
+// Descendants mengembalikan id unit tersebut beserta seluruh turunannya.
+func (r *orgUnitRepo) Descendants(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE tree AS (
+			SELECT id FROM org_units WHERE id = $1
+			UNION ALL
+			SELECT o.id FROM org_units o JOIN tree t ON o.parent_id = t.id
+		)
+		SELECT id FROM tree
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var unitID uuid.UUID
+		if err := rows.Scan(&unitID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, unitID)
+	}
+
+	if len(ids) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return ids, rows.Err()
+}
+
+// DepartmentOf mencari unit bertipe department terdekat dari id (id itu
+// sendiri atau leluhurnya) lalu mengembalikan department tersebut beserta
+// turunannya. sql.ErrNoRows kalau id tidak berada di bawah department mana pun.
+func (r *orgUnitRepo) DepartmentOf(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var deptID uuid.UUID
+
+	err := r.db.QueryRowContext(ctx, `
+		WITH RECURSIVE up AS (
+			SELECT id, parent_id, type, 0 AS depth FROM org_units WHERE id = $1
+			UNION ALL
+			SELECT o.id, o.parent_id, o.type, up.depth + 1
+			FROM org_units o JOIN up ON o.id = up.parent_id
+		)
+		SELECT id FROM up
+		WHERE type = $2
+		ORDER BY depth
+		LIMIT 1
+	`, id, models.OrgUnitDepartment).Scan(&deptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Descendants(ctx, deptID)
+}
+
+func (r *orgUnitRepo) Create(ctx context.Context, u models.OrgUnit) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO org_units (id, parent_id, name, type)
+		VALUES ($1, $2, $3, $4)
+	`, u.ID, u.ParentID, u.Name, u.Type)
+	return mapUniqueViolation(err)
+}
+
+func (r *orgUnitRepo) Update(ctx context.Context, u models.OrgUnit) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE org_units SET name = $2 WHERE id = $1
+	`, u.ID, u.Name)
+	return mapUniqueViolation(err)
+}
+
+func (r *orgUnitRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	var inUse bool
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM org_units WHERE parent_id = $1) OR
+			EXISTS (SELECT 1 FROM students WHERE org_unit_id = $1) OR
+			EXISTS (SELECT 1 FROM lecturers WHERE org_unit_id = $1) OR
+			EXISTS (SELECT 1 FROM users WHERE scope_org_unit_id = $1)
+	`, id).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrOrgUnitInUse
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM org_units WHERE id = $1`, id)
+	return err
+}
+
+func (r *orgUnitRepo) AssignStudent(ctx context.Context, unitID uuid.UUID, studentID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE students SET org_unit_id = $1 WHERE id = $2
+	`, unitID, studentID)
+	return err
+}
+
+func (r *orgUnitRepo) AssignLecturer(ctx context.Context, unitID uuid.UUID, lecturerID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE lecturers SET org_unit_id = $1 WHERE id = $2
+	`, unitID, lecturerID)
+	return err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, t models.PasswordResetToken) error
+	FindByHash(ctx context.Context, tokenHash string) (models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, tokenHash string) (bool, error)
+}
+
+type passwordResetRepo struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepo{db}
+}
+
+func (r *passwordResetRepo) Create(ctx context.Context, t models.PasswordResetToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, t.ID, t.UserID, t.TokenHash, t.ExpiresAt)
+	return err
+}
+
+func (r *passwordResetRepo) FindByHash(ctx context.Context, tokenHash string) (models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt,
+	)
+
+	return t, err
+}
+
+// MarkUsed mengembalikan false kalau token sudah dipakai atau kadaluarsa.
+func (r *passwordResetRepo) MarkUsed(ctx context.Context, tokenHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	`, tokenHash)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
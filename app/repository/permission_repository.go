@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type PermissionRepository interface {
+	FindAll(ctx context.Context) ([]models.Permission, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.Permission, error)
+	FindByRole(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error)
+	// FindByUser mengembalikan permission efektif user (lewat role-nya).
+	FindByUser(ctx context.Context, userID uuid.UUID) ([]models.Permission, error)
+	Create(ctx context.Context, p models.Permission) error
+	Update(ctx context.Context, p models.Permission) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type permissionRepo struct {
+	db *sql.DB
+}
+
+func NewPermissionRepository(db *sql.DB) PermissionRepository {
+	return &permissionRepo{db}
+}
+
+func (r *permissionRepo) query(ctx context.Context, query string, args ...any) ([]models.Permission, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.IsSystem); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, nil
+}
+
+func (r *permissionRepo) FindAll(ctx context.Context) ([]models.Permission, error) {
+	return r.query(ctx, `
+		SELECT id, name, COALESCE(description, ''), is_system
+		FROM permissions
+		ORDER BY name
+	`)
+}
+
+func (r *permissionRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Permission, error) {
+	var p models.Permission
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), is_system
+		FROM permissions
+		WHERE id = $1
+	`, id).Scan(&p.ID, &p.Name, &p.Description, &p.IsSystem)
+
+	return p, err
+}
+
+func (r *permissionRepo) FindByRole(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error) {
+	return r.query(ctx, `
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.is_system
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.name
+	`, roleID)
+}
+
+func (r *permissionRepo) FindByUser(ctx context.Context, userID uuid.UUID) ([]models.Permission, error) {
+	return r.query(ctx, `
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.is_system
+		FROM users u
+		JOIN role_permissions rp ON rp.role_id = u.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE u.id = $1
+		ORDER BY p.name
+	`, userID)
+}
+
+func (r *permissionRepo) Create(ctx context.Context, p models.Permission) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO permissions (id, name, description, is_system)
+		VALUES ($1, $2, $3, false)
+	`, p.ID, p.Name, p.Description)
+	return mapUniqueViolation(err)
+}
+
+func (r *permissionRepo) Update(ctx context.Context, p models.Permission) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE permissions SET name = $2, description = $3 WHERE id = $1
+	`, p.ID, p.Name, p.Description)
+	return mapUniqueViolation(err)
+}
+
+func (r *permissionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM role_permissions
+		WHERE permission_id = $1 AND permission_id IN (SELECT id FROM permissions WHERE is_system = false)
+	`, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM permissions WHERE id = $1 AND is_system = false
+	`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
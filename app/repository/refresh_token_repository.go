@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	CreateFamily(ctx context.Context, f models.RefreshTokenFamily) error
+	FindFamilyByID(ctx context.Context, id uuid.UUID) (models.RefreshTokenFamily, error)
+	FindActiveFamiliesByUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshTokenFamily, error)
+	TouchFamily(ctx context.Context, id uuid.UUID, ip string, userAgent string) error
+	RevokeFamily(ctx context.Context, id uuid.UUID, reason string) error
+	Create(ctx context.Context, t models.RefreshToken) error
+	FindByJTI(ctx context.Context, jti uuid.UUID) (models.RefreshToken, error)
+	MarkUsed(ctx context.Context, jti uuid.UUID) (bool, error)
+}
+
+type refreshTokenRepo struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{db}
+}
+
+func (r *refreshTokenRepo) CreateFamily(ctx context.Context, f models.RefreshTokenFamily) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_token_families (
+			id, user_id, ip, user_agent, created_at, last_used_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $5)
+	`, f.ID, f.UserID, f.IP, f.UserAgent, f.CreatedAt)
+	return err
+}
+
+func (r *refreshTokenRepo) FindFamilyByID(ctx context.Context, id uuid.UUID) (models.RefreshTokenFamily, error) {
+	var f models.RefreshTokenFamily
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, ip, user_agent, created_at, last_used_at,
+			revoked_at, revoked_reason
+		FROM refresh_token_families
+		WHERE id = $1
+	`, id).Scan(
+		&f.ID, &f.UserID, &f.IP, &f.UserAgent, &f.CreatedAt, &f.LastUsedAt,
+		&f.RevokedAt, &f.RevokedReason,
+	)
+
+	return f, err
+}
+
+// FindActiveFamiliesByUser mengembalikan session yang belum dicabut dan
+// masih punya refresh token yang bisa dipakai.
+func (r *refreshTokenRepo) FindActiveFamiliesByUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshTokenFamily, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.id, f.user_id, f.ip, f.user_agent, f.created_at, f.last_used_at,
+			f.revoked_at, f.revoked_reason
+		FROM refresh_token_families f
+		WHERE f.user_id = $1
+		  AND f.revoked_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM refresh_tokens t
+			WHERE t.family_id = f.id
+			  AND t.used_at IS NULL
+			  AND t.expires_at > NOW()
+		  )
+		ORDER BY f.last_used_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.RefreshTokenFamily
+	for rows.Next() {
+		var f models.RefreshTokenFamily
+		if err := rows.Scan(
+			&f.ID, &f.UserID, &f.IP, &f.UserAgent, &f.CreatedAt, &f.LastUsedAt,
+			&f.RevokedAt, &f.RevokedReason,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+func (r *refreshTokenRepo) TouchFamily(ctx context.Context, id uuid.UUID, ip string, userAgent string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_token_families
+		SET last_used_at = NOW(), ip = $2, user_agent = $3
+		WHERE id = $1
+	`, id, ip, userAgent)
+	return err
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, id uuid.UUID, reason string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_token_families
+		SET revoked_at = NOW(), revoked_reason = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id, reason)
+	return err
+}
+
+func (r *refreshTokenRepo) Create(ctx context.Context, t models.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (jti, family_id, user_id, issued_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, t.JTI, t.FamilyID, t.UserID, t.IssuedAt, t.ExpiresAt)
+	return err
+}
+
+func (r *refreshTokenRepo) FindByJTI(ctx context.Context, jti uuid.UUID) (models.RefreshToken, error) {
+	var t models.RefreshToken
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT jti, family_id, user_id, issued_at, expires_at, used_at
+		FROM refresh_tokens
+		WHERE jti = $1
+	`, jti).Scan(
+		&t.JTI, &t.FamilyID, &t.UserID, &t.IssuedAt, &t.ExpiresAt, &t.UsedAt,
+	)
+
+	return t, err
+}
+
+// MarkUsed mengembalikan false kalau token sudah pernah dipakai (reuse).
+func (r *refreshTokenRepo) MarkUsed(ctx context.Context, jti uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = NOW()
+		WHERE jti = $1 AND used_at IS NULL
+	`, jti)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrRegistrationNotPending = errors.New("registration is not pending approval")
+
+type RegistrationRepository interface {
+	Create(ctx context.Context, r models.StudentRegistration) error
+	FindByID(ctx context.Context, id uuid.UUID) (models.StudentRegistration, error)
+	FindByStatus(ctx context.Context, status string) ([]models.StudentRegistration, error)
+	// ExistsActive mengecek pendaftaran yang masih berjalan untuk email / NIM.
+	ExistsActive(ctx context.Context, email string, studentID string) (bool, error)
+	DeleteUnverifiedBefore(ctx context.Context, before time.Time) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) (bool, error)
+	Approve(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, user models.Users, student models.Student) error
+	Reject(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, reason string) (bool, error)
+}
+
+type registrationRepo struct {
+	db *sql.DB
+}
+
+func NewRegistrationRepository(db *sql.DB) RegistrationRepository {
+	return &registrationRepo{db}
+}
+
+const registrationColumns = `
+	id, full_name, email, student_id, program_study, academic_year, password_hash,
+	status, email_verified_at, reviewed_by, reviewed_at, reject_reason, user_id, created_at
+`
+
+func scanRegistration(row interface{ Scan(...any) error }) (models.StudentRegistration, error) {
+	var r models.StudentRegistration
+	err := row.Scan(
+		&r.ID, &r.FullName, &r.Email, &r.StudentID, &r.ProgramStudy, &r.AcademicYear, &r.PasswordHash,
+		&r.Status, &r.EmailVerifiedAt, &r.ReviewedBy, &r.ReviewedAt, &r.RejectReason, &r.UserID, &r.CreatedAt,
+	)
+	return r, err
+}
+
+func (r *registrationRepo) Create(ctx context.Context, reg models.StudentRegistration) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO student_registrations (
+			id, full_name, email, student_id, program_study, academic_year,
+			password_hash, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+	`,
+		reg.ID, reg.FullName, reg.Email, reg.StudentID, reg.ProgramStudy, reg.AcademicYear,
+		reg.PasswordHash, models.RegistrationPendingVerification,
+	)
+	return err
+}
+
+func (r *registrationRepo) FindByID(ctx context.Context, id uuid.UUID) (models.StudentRegistration, error) {
+	return scanRegistration(r.db.QueryRowContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM student_registrations
+		WHERE id = $1
+	`, id))
+}
+
+func (r *registrationRepo) FindByStatus(ctx context.Context, status string) ([]models.StudentRegistration, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+registrationColumns+`
+		FROM student_registrations
+		WHERE status = $1
+		ORDER BY created_at
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.StudentRegistration
+	for rows.Next() {
+		reg, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, reg)
+	}
+	return list, nil
+}
+
+func (r *registrationRepo) ExistsActive(ctx context.Context, email string, studentID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM student_registrations
+			WHERE (LOWER(email) = LOWER($1) OR student_id = $2)
+			AND status IN ('pending_verification', 'pending_approval')
+		)
+	`, email, studentID).Scan(&exists)
+	return exists, err
+}
+
+// DeleteUnverifiedBefore membuang pendaftaran yang link verifikasinya sudah
+// kedaluwarsa supaya email / NIM yang sama bisa mendaftar ulang.
+func (r *registrationRepo) DeleteUnverifiedBefore(ctx context.Context, before time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM student_registrations
+		WHERE status = 'pending_verification' AND created_at < $1
+	`, before)
+	return err
+}
+
+func (r *registrationRepo) MarkEmailVerified(ctx context.Context, id uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE student_registrations
+		SET status = 'pending_approval', email_verified_at = NOW()
+		WHERE id = $1 AND status = 'pending_verification'
+	`, id)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Approve membuat user dan profil mahasiswa lalu menandai pendaftaran
+// approved dalam satu transaksi.
+func (r *registrationRepo) Approve(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, u models.Users, s models.Student) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// kunci baris pendaftaran dulu supaya approve ganda mendapat
+	// ErrRegistrationNotPending, bukan error unique username
+	var pending bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT status = 'pending_approval'
+		FROM student_registrations
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&pending)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !pending) {
+		return ErrRegistrationNotPending
+	}
+	if err != nil {
+		return err
+	}
+
+	// users dan students dibuat dulu: student_registrations.user_id
+	// mereferensikan users(id)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id)
+		VALUES ($1,$2,$3,$4,$5,$6)
+	`, u.ID, u.Username, u.Email, u.PasswordHash, u.FullName, u.RoleID)
+	if err != nil {
+		return err
+	}
+
+	var advisorID *uuid.UUID
+	if s.AdvisorID != uuid.Nil {
+		advisorID = &s.AdvisorID
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO students (
+			id, user_id, student_id,
+			program_study, academic_year, advisor_id
+		) VALUES ($1, $2, $3, $4, $5, $6)
+	`, s.ID, u.ID, s.StudentID, s.ProgramStudy, s.AcademicYear, advisorID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE student_registrations
+		SET status = 'approved', reviewed_by = $2, reviewed_at = NOW(), user_id = $3
+		WHERE id = $1 AND status = 'pending_approval'
+	`, id, reviewer, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n != 1 {
+		return ErrRegistrationNotPending
+	}
+
+	return tx.Commit()
+}
+
+func (r *registrationRepo) Reject(ctx context.Context, id uuid.UUID, reviewer uuid.UUID, reason string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE student_registrations
+		SET status = 'rejected', reviewed_by = $2, reviewed_at = NOW(), reject_reason = $3
+		WHERE id = $1 AND status = 'pending_approval'
+	`, id, reviewer, reason)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type RoleRepository interface {
+	FindAll(ctx context.Context) ([]models.Role, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.Role, error)
+	FindByName(ctx context.Context, name string) (models.Role, error)
+	Create(ctx context.Context, role models.Role) error
+	Update(ctx context.Context, role models.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountUsers(ctx context.Context, id uuid.UUID) (int, error)
+	UpdateMFARequired(ctx context.Context, id uuid.UUID, required bool) error
+	AttachPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+	DetachPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+}
+
+type roleRepo struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) RoleRepository {
+	return &roleRepo{db}
+}
+
+func (r *roleRepo) FindAll(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), mfa_required, is_system
+		FROM roles
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Role
+	for rows.Next() {
+		var role models.Role
+		if err := rows.Scan(
+			&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.IsSystem,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, role)
+	}
+	return list, nil
+}
+
+func (r *roleRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Role, error) {
+	var role models.Role
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), mfa_required, is_system
+		FROM roles
+		WHERE id = $1
+	`, id).Scan(
+		&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.IsSystem,
+	)
+
+	return role, err
+}
+
+func (r *roleRepo) FindByName(ctx context.Context, name string) (models.Role, error) {
+	var role models.Role
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, COALESCE(description, ''), mfa_required, is_system
+		FROM roles
+		WHERE LOWER(name) = LOWER($1)
+	`, name).Scan(
+		&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.IsSystem,
+	)
+
+	return role, err
+}
+
+func (r *roleRepo) Create(ctx context.Context, role models.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO roles (id, name, description, mfa_required, is_system)
+		VALUES ($1, $2, $3, $4, false)
+	`, role.ID, role.Name, role.Description, role.MFARequired)
+	return mapUniqueViolation(err)
+}
+
+func (r *roleRepo) Update(ctx context.Context, role models.Role) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE roles SET name = $2, description = $3 WHERE id = $1
+	`, role.ID, role.Name, role.Description)
+	return mapUniqueViolation(err)
+}
+
+// Delete menghapus role beserta mapping permission-nya. Role sistem tidak
+// pernah terhapus walaupun service lupa mengecek.
+func (r *roleRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND role_id IN (SELECT id FROM roles WHERE is_system = false)
+	`, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM roles WHERE id = $1 AND is_system = false
+	`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepo) CountUsers(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM users WHERE role_id = $1
+	`, id).Scan(&count)
+	return count, err
+}
+
+func (r *roleRepo) UpdateMFARequired(ctx context.Context, id uuid.UUID, required bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE roles SET mfa_required = $2 WHERE id = $1
+	`, id, required)
+	return err
+}
+
+func (r *roleRepo) AttachPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, roleID, permissionID)
+	return err
+}
+
+func (r *roleRepo) DetachPermission(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2
+	`, roleID, permissionID)
+	return err
+}
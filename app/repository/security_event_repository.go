@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type SecurityEventRepository interface {
+	Record(ctx context.Context, e models.SecurityEvent) error
+	FindByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.SecurityEvent, error)
+}
+
+type securityEventRepo struct {
+	db *sql.DB
+}
+
+func NewSecurityEventRepository(db *sql.DB) SecurityEventRepository {
+	return &securityEventRepo{db}
+}
+
+func (r *securityEventRepo) Record(ctx context.Context, e models.SecurityEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO security_events (id, user_id, event_type, ip, user_agent, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`, e.ID, e.UserID, e.EventType, e.IP, e.UserAgent, e.Detail)
+	return err
+}
+
+func (r *securityEventRepo) FindByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.SecurityEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, event_type, COALESCE(ip, ''), COALESCE(user_agent, ''),
+			COALESCE(detail, ''), created_at
+		FROM security_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.SecurityEvent
+	for rows.Next() {
+		var e models.SecurityEvent
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &e.EventType, &e.IP, &e.UserAgent, &e.Detail, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, sa models.ServiceAccount) error
+	FindAll(ctx context.Context) ([]models.ServiceAccount, error)
+	FindByID(ctx context.Context, id uuid.UUID) (models.ServiceAccount, error)
+	Deactivate(ctx context.Context, id uuid.UUID) error
+	CreateKey(ctx context.Context, key models.APIKey) error
+	FindKeysByAccount(ctx context.Context, accountID uuid.UUID) ([]models.APIKey, error)
+	FindKeyByPrefix(ctx context.Context, prefix string) (models.APIKey, error)
+	RevokeKey(ctx context.Context, accountID uuid.UUID, keyID uuid.UUID) error
+	TouchKey(ctx context.Context, keyID uuid.UUID) error
+}
+
+type serviceAccountRepo struct {
+	db *sql.DB
+}
+
+func NewServiceAccountRepository(db *sql.DB) ServiceAccountRepository {
+	return &serviceAccountRepo{db}
+}
+
+func (r *serviceAccountRepo) Create(ctx context.Context, sa models.ServiceAccount) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO service_accounts (id, name, description, is_active, created_by, created_at)
+		VALUES ($1, $2, $3, true, $4, NOW())
+	`, sa.ID, sa.Name, sa.Description, sa.CreatedBy)
+	return err
+}
+
+func (r *serviceAccountRepo) FindAll(ctx context.Context) ([]models.ServiceAccount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, description, is_active, created_by, created_at
+		FROM service_accounts
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.ServiceAccount
+	for rows.Next() {
+		var sa models.ServiceAccount
+		if err := rows.Scan(
+			&sa.ID, &sa.Name, &sa.Description, &sa.IsActive, &sa.CreatedBy, &sa.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, sa)
+	}
+	return list, nil
+}
+
+func (r *serviceAccountRepo) FindByID(ctx context.Context, id uuid.UUID) (models.ServiceAccount, error) {
+	var sa models.ServiceAccount
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, is_active, created_by, created_at
+		FROM service_accounts
+		WHERE id = $1
+	`, id).Scan(
+		&sa.ID, &sa.Name, &sa.Description, &sa.IsActive, &sa.CreatedBy, &sa.CreatedAt,
+	)
+
+	return sa, err
+}
+
+func (r *serviceAccountRepo) Deactivate(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE service_accounts SET is_active = false WHERE id = $1
+	`, id)
+	return err
+}
+
+func (r *serviceAccountRepo) CreateKey(ctx context.Context, k models.APIKey) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (
+			id, service_account_id, prefix, key_hash, scopes, expires_at, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`, k.ID, k.ServiceAccountID, k.Prefix, k.KeyHash, pq.Array(k.Scopes), k.ExpiresAt)
+	return err
+}
+
+func (r *serviceAccountRepo) FindKeysByAccount(ctx context.Context, accountID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, service_account_id, prefix, key_hash, scopes,
+			expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE service_account_id = $1
+		ORDER BY created_at DESC
+	`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.APIKey
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(
+			&k.ID, &k.ServiceAccountID, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes),
+			&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	return list, nil
+}
+
+func (r *serviceAccountRepo) FindKeyByPrefix(ctx context.Context, prefix string) (models.APIKey, error) {
+	var k models.APIKey
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, service_account_id, prefix, key_hash, scopes,
+			expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE prefix = $1
+	`, prefix).Scan(
+		&k.ID, &k.ServiceAccountID, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes),
+		&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt,
+	)
+
+	return k, err
+}
+
+func (r *serviceAccountRepo) RevokeKey(ctx context.Context, accountID uuid.UUID, keyID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND service_account_id = $2 AND revoked_at IS NULL
+	`, keyID, accountID)
+	return err
+}
+
+func (r *serviceAccountRepo) TouchKey(ctx context.Context, keyID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = NOW() WHERE id = $1
+	`, keyID)
+	return err
+}
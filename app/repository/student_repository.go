@@ -28,8 +28,8 @@ func (r *studentRepo) Create(ctx context.Context, s models.Student) error {
 	query := `
 		INSERT INTO students (
 			id, user_id, student_id,
-			program_study, academic_year, advisor_id
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			program_study, academic_year, advisor_id, org_unit_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -39,6 +39,7 @@ func (r *studentRepo) Create(ctx context.Context, s models.Student) error {
 		s.ProgramStudy,
 		s.AcademicYear,
 		s.AdvisorID,
+		s.OrgUnitID,
 	)
 
 	return err
@@ -48,14 +49,14 @@ func (r *studentRepo) FindByUserID(ctx context.Context, userID string) (models.S
 	var s models.Student
 
 	query := `
-        SELECT id, user_id, student_id, program_study, academic_year, advisor_id
+        SELECT id, user_id, student_id, program_study, academic_year, advisor_id, org_unit_id
         FROM students
         WHERE user_id = $1
         LIMIT 1
     `
 
 	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy, &s.AcademicYear, &s.AdvisorID,
+		&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy, &s.AcademicYear, &s.AdvisorID, &s.OrgUnitID,
 	)
 
 	return s, err
@@ -72,7 +73,8 @@ func (r *studentRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Studen
 			student_id,
 			program_study,
 			academic_year,
-			advisor_id
+			advisor_id,
+			org_unit_id
 		FROM students
 		WHERE id = $1
 	`, id).Scan(
@@ -82,6 +84,7 @@ func (r *studentRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Studen
 		&s.ProgramStudy,
 		&s.AcademicYear,
 		&s.AdvisorID,
+		&s.OrgUnitID,
 	)
 
 	if err != nil {
@@ -91,10 +94,12 @@ func (r *studentRepo) FindByID(ctx context.Context, id uuid.UUID) (models.Studen
 	return s, nil
 }
 func (r *studentRepo) FindAll(ctx context.Context) ([]models.Student, error) {
+	cond, args := scopeFilter(ctx, "org_unit_id", nil)
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, user_id, student_id, program_study, academic_year, advisor_id
+		SELECT id, user_id, student_id, program_study, academic_year, advisor_id, org_unit_id
 		FROM students
-	`)
+		WHERE `+cond, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +110,7 @@ func (r *studentRepo) FindAll(ctx context.Context) ([]models.Student, error) {
 		var s models.Student
 		if err := rows.Scan(
 			&s.ID, &s.UserID, &s.StudentID,
-			&s.ProgramStudy, &s.AcademicYear, &s.AdvisorID,
+			&s.ProgramStudy, &s.AcademicYear, &s.AdvisorID, &s.OrgUnitID,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas/app/models"
+
+	"github.com/google/uuid"
+)
+
+type SubmissionRepository interface {
+	FindByAchievement(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementSubmission, error)
+}
+
+type submissionRepo struct {
+	db *sql.DB
+}
+
+func NewSubmissionRepository(db *sql.DB) SubmissionRepository {
+	return &submissionRepo{db}
+}
+
+func (r *submissionRepo) FindByAchievement(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementSubmission, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, achievement_id, round, snapshot, changes, submitted_by, submitted_at,
+			outcome, reviewed_by, reviewed_at, delegation_id, note
+		FROM achievement_submissions
+		WHERE achievement_id = $1
+		ORDER BY round
+	`, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.AchievementSubmission
+	for rows.Next() {
+		var (
+			s                 models.AchievementSubmission
+			snapshot, changes []byte
+		)
+		if err := rows.Scan(
+			&s.ID, &s.AchievementID, &s.Round, &snapshot, &changes, &s.SubmittedBy, &s.SubmittedAt,
+			&s.Outcome, &s.ReviewedBy, &s.ReviewedAt, &s.DelegationID, &s.Note,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(snapshot, &s.Snapshot); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &s.Changes); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
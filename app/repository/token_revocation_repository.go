@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type TokenRevocationRepository interface {
+	Revoke(ctx context.Context, jti string, exp time.Time) error
+	IsRevoked(ctx context.Context, jtis ...string) (bool, error)
+	PurgeExpired(ctx context.Context) error
+}
+
+type tokenRevocationRepo struct {
+	db *sql.DB
+}
+
+func NewTokenRevocationRepository(db *sql.DB) TokenRevocationRepository {
+	return &tokenRevocationRepo{db}
+}
+
+func (r *tokenRevocationRepo) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at, revoked_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, exp)
+	return err
+}
+
+// IsRevoked bernilai true kalau salah satu jti (token / session) sudah dicabut.
+func (r *tokenRevocationRepo) IsRevoked(ctx context.Context, jtis ...string) (bool, error) {
+	var exists bool
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM revoked_tokens
+			WHERE jti = ANY($1) AND expires_at > NOW()
+		)
+	`, pq.Array(jtis)).Scan(&exists)
+
+	return exists, err
+}
+
+func (r *tokenRevocationRepo) PurgeExpired(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM revoked_tokens
+		WHERE expires_at <= NOW()
+	`)
+	return err
+}
@@ -14,8 +14,9 @@ type AdminUserRepository interface {
 	Create(ctx context.Context, user models.Users) error
 	Update(ctx context.Context, user models.Users) error
 	SoftDelete(ctx context.Context, id string) error
-	UpdateRole(ctx context.Context, id string, roleID uuid.UUID) error
+	UpdateRole(ctx context.Context, id string, roleID uuid.UUID, scopeOrgUnitID *uuid.UUID) error
 	GetUserPermissions(ctx context.Context, userID string) ([]string, error)
+	Unlock(ctx context.Context, id string) error
 }
 
 type adminUserRepo struct {
@@ -26,12 +27,19 @@ func NewAdminUserRepository(db *sql.DB) AdminUserRepository {
 	return &adminUserRepo{db}
 }
 
+// userOrgUnit adalah unit seorang user: unit mahasiswa / dosennya, atau
+// scope role admin-nya.
+const userOrgUnit = `COALESCE(s.org_unit_id, l.org_unit_id, u.scope_org_unit_id)`
+
 func (r *adminUserRepo) FindAll(ctx context.Context) ([]models.Users, error) {
+	cond, args := scopeFilter(ctx, userOrgUnit, nil)
+
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, username, email, full_name, role_id, is_active
-		FROM users
-		WHERE is_active = true
-	`)
+		SELECT u.id, u.username, u.email, u.full_name, u.role_id, u.is_active, u.scope_org_unit_id
+		FROM users u
+		LEFT JOIN students s ON s.user_id = u.id
+		LEFT JOIN lecturers l ON l.user_id = u.id
+		WHERE u.is_active = true AND `+cond, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -41,35 +49,45 @@ func (r *adminUserRepo) FindAll(ctx context.Context) ([]models.Users, error) {
 	var users []models.Users
 	for rows.Next() {
 		var u models.Users
-		rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID, &u.IsActive)
+		rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID, &u.IsActive, &u.ScopeOrgUnitID)
 		users = append(users, u)
 	}
 	return users, nil
 }
 
+// FindByID ikut dibatasi scope, jadi admin unit mendapat sql.ErrNoRows
+// untuk user di luar unit-nya.
 func (r *adminUserRepo) FindByID(ctx context.Context, id string) (models.Users, error) {
 	var u models.Users
+
+	cond, args := scopeFilter(ctx, userOrgUnit, []interface{}{id})
+
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, username, email, full_name, role_id, is_active
-		FROM users WHERE id = $1
-	`, id).Scan(
+		SELECT u.id, u.username, u.email, u.full_name, u.role_id, u.is_active,
+			u.locked_until, u.token_version, u.scope_org_unit_id
+		FROM users u
+		LEFT JOIN students s ON s.user_id = u.id
+		LEFT JOIN lecturers l ON l.user_id = u.id
+		WHERE u.id = $1 AND `+cond, args...).Scan(
 		&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID, &u.IsActive,
+		&u.LockedUntil, &u.TokenVersion, &u.ScopeOrgUnitID,
 	)
 	return u, err
 }
 
 func (r *adminUserRepo) Create(ctx context.Context, u models.Users) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id)
-		VALUES ($1,$2,$3,$4,$5,$6)
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, scope_org_unit_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
 	`,
-		u.ID, u.Username, u.Email, u.PasswordHash, u.FullName, u.RoleID)
+		u.ID, u.Username, u.Email, u.PasswordHash, u.FullName, u.RoleID, u.ScopeOrgUnitID)
 	return err
 }
 
 func (r *adminUserRepo) Update(ctx context.Context, u models.Users) error {
 	_, err := r.db.ExecContext(ctx, `
-		UPDATE users SET username=$2, email=$3, full_name=$4, role_id=$5
+		UPDATE users SET username=$2, email=$3, full_name=$4, role_id=$5,
+			token_version = token_version + CASE WHEN role_id <> $5 THEN 1 ELSE 0 END
 		WHERE id=$1
 	`,
 		u.ID, u.Username, u.Email, u.FullName, u.RoleID)
@@ -78,15 +96,25 @@ func (r *adminUserRepo) Update(ctx context.Context, u models.Users) error {
 
 func (r *adminUserRepo) SoftDelete(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx, `
-		UPDATE users SET is_active=false WHERE id=$1
+		UPDATE users SET is_active=false, token_version = token_version + 1 WHERE id=$1
 	`, id)
 	return err
 }
 
-func (r *adminUserRepo) UpdateRole(ctx context.Context, id string, roleID uuid.UUID) error {
+// UpdateRole sekaligus mengganti scope unit role tersebut (nil = seluruh universitas).
+func (r *adminUserRepo) UpdateRole(ctx context.Context, id string, roleID uuid.UUID, scopeOrgUnitID *uuid.UUID) error {
 	_, err := r.db.ExecContext(ctx, `
-		UPDATE users SET role_id=$2 WHERE id=$1
-	`, id, roleID)
+		UPDATE users SET role_id=$2, scope_org_unit_id=$3, token_version = token_version + 1 WHERE id=$1
+	`, id, roleID, scopeOrgUnitID)
+	return err
+}
+
+func (r *adminUserRepo) Unlock(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users
+		SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+		WHERE id = $1
+	`, id)
 	return err
 }
 
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// achievementVersions menyimpan setiap perubahan isi prestasi sebagai versi
+// baru dan melayani list / detail / diff versi untuk mahasiswa & reviewer.
+type achievementVersions struct {
+	repo     repository.AchievementRepository
+	mongo    repository.MongoAchievementRepository
+	versions repository.AchievementVersionRepository
+	policies *policy.Resolver
+}
+
+// Write menjalankan write dokumen lalu menyimpan isi yang dikembalikannya
+// (dokumen sebagaimana tersimpan) sebagai versi baru. Versi hanya ditulis
+// untuk isi yang benar-benar tersimpan dan tidak pernah dihapus lagi.
+func (v *achievementVersions) Write(
+	ctx context.Context,
+	hexID string,
+	change string,
+	authorID string,
+	write func() (models.AchievementSnapshot, error),
+) (models.AchievementVersion, error) {
+	content, err := write()
+	if err != nil {
+		return models.AchievementVersion{}, err
+	}
+
+	return v.versions.Append(ctx, hexID, change, content, authorID)
+}
+
+// Record menyimpan isi dokumen hexID saat ini sebagai versi baru.
+func (v *achievementVersions) Record(ctx context.Context, hexID string, change string, authorID string) (models.AchievementVersion, error) {
+	detail, err := v.mongo.FindByHexID(ctx, hexID)
+	if err != nil {
+		return models.AchievementVersion{}, err
+	}
+
+	return v.versions.Append(ctx, hexID, change, detail.Snapshot(), authorID)
+}
+
+// EnsureBaseline menyimpan isi lama sebagai versi pertama untuk prestasi
+// yang dibuat sebelum ada versioning, sebelum isinya diubah.
+func (v *achievementVersions) EnsureBaseline(ctx context.Context, hexID string) error {
+	list, err := v.versions.FindByAchievement(ctx, hexID)
+	if err != nil || len(list) > 0 {
+		return err
+	}
+
+	_, err = v.Record(ctx, hexID, models.VersionBaseline, "system")
+	return err
+}
+
+func (v *achievementVersions) Delete(ctx context.Context, hexID string) error {
+	return v.versions.DeleteByAchievement(ctx, hexID)
+}
+
+func (v *achievementVersions) List(c *fiber.Ctx) ([]models.AchievementVersion, int, string) {
+	ref, status, msg := authorizeAchievement(c, v.repo, v.policies, policy.ActionRead)
+	if status != 0 {
+		return nil, status, msg
+	}
+
+	list, err := v.versions.FindByAchievement(c.Context(), ref.MongoAchievementID)
+	if err != nil {
+		return nil, 500, "failed load versions"
+	}
+
+	return list, 0, ""
+}
+
+func (v *achievementVersions) Get(c *fiber.Ctx) (models.AchievementVersion, int, string) {
+	ref, status, msg := authorizeAchievement(c, v.repo, v.policies, policy.ActionRead)
+	if status != 0 {
+		return models.AchievementVersion{}, status, msg
+	}
+
+	number, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return models.AchievementVersion{}, 400, "invalid version"
+	}
+
+	version, err := v.versions.FindVersion(c.Context(), ref.MongoAchievementID, number)
+	if err != nil {
+		return models.AchievementVersion{}, 404, "version not found"
+	}
+
+	return version, 0, ""
+}
+
+// Diff membandingkan versi ?from dan ?to per field. Tanpa ?to, dibandingkan
+// dengan versi terakhir.
+func (v *achievementVersions) Diff(c *fiber.Ctx) (fiber.Map, int, string) {
+	ref, status, msg := authorizeAchievement(c, v.repo, v.policies, policy.ActionRead)
+	if status != 0 {
+		return nil, status, msg
+	}
+
+	list, err := v.versions.FindByAchievement(c.Context(), ref.MongoAchievementID)
+	if err != nil {
+		return nil, 500, "failed load versions"
+	}
+	if len(list) == 0 {
+		return nil, 404, "achievement has no versions yet"
+	}
+
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		return nil, 400, "from is required"
+	}
+
+	to := list[len(list)-1].Version
+	if c.Query("to") != "" {
+		if to, err = strconv.Atoi(c.Query("to")); err != nil {
+			return nil, 400, "invalid to"
+		}
+	}
+
+	var older, newer *models.AchievementVersion
+	for i := range list {
+		if list[i].Version == from {
+			older = &list[i]
+		}
+		if list[i].Version == to {
+			newer = &list[i]
+		}
+	}
+	if older == nil || newer == nil {
+		return nil, 404, "version not found"
+	}
+
+	return fiber.Map{
+		"from":    older,
+		"to":      newer,
+		"changes": workflow.Diff(older.Content, newer.Content),
+	}, 0, ""
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// achievementWorkflow adalah satu-satunya jalan untuk mengubah status
+// prestasi: validasi transisi + policy, lalu status, round submission dan
+// stage approval ditulis dalam satu transaksi, baru history di Mongo.
+type achievementWorkflow struct {
+	repo        repository.AchievementRepository
+	mongo       repository.MongoAchievementRepository
+	submissions repository.SubmissionRepository
+	approvals   repository.ApprovalRepository
+	chains      repository.ApprovalChainRepository
+	transitions repository.TransitionRepository
+	policies    *policy.Resolver
+}
+
+// Transition menjalankan event terhadap prestasi :id. Kalau status != 0,
+// request harus dihentikan dengan kode dan pesan yang dikembalikan.
+func (w *achievementWorkflow) Transition(c *fiber.Ctx, event workflow.Event, note string) (models.AchievementRef, int, string) {
+	refID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.AchievementRef{}, 400, "invalid id"
+	}
+
+	actor, err := w.policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return models.AchievementRef{}, 500, "failed to check permissions"
+	}
+
+	return w.TransitionAs(c, actor, refID, event, note)
+}
+
+// TransitionAs sama seperti Transition untuk prestasi refID dengan actor
+// yang sudah di-resolve (mis. item pada operasi bulk).
+func (w *achievementWorkflow) TransitionAs(c *fiber.Ctx, actor policy.Actor, refID uuid.UUID, event workflow.Event, note string) (models.AchievementRef, int, string) {
+	t, ok := workflow.Lookup(event)
+	if !ok {
+		return models.AchievementRef{}, 400, "unknown workflow event"
+	}
+
+	ref, delegationID, status, msg := authorizeReviewAs(c.Context(), w.repo, w.policies, actor, t.Action, refID)
+	if status != 0 {
+		return models.AchievementRef{}, status, msg
+	}
+
+	// prestasi dengan approval chain: verify hanya menyetujui stage yang
+	// aktif, status baru verified setelah stage terakhir disetujui
+	var stage *models.AchievementApproval
+	if t.Effects&workflow.RecordApproval != 0 {
+		var err error
+		if stage, err = w.approvals.Pending(c.Context(), ref.ID); err != nil {
+			fmt.Println("ERROR load approval stage:", err)
+			return models.AchievementRef{}, 500, "failed load approval stage"
+		}
+		if stage != nil && !stage.Final {
+			t, _ = workflow.Lookup(workflow.ApproveStage)
+		}
+	}
+
+	if !t.Allows(ref.Status) {
+		return models.AchievementRef{}, 409, fmt.Sprintf("cannot %s an achievement with status %s", strings.ReplaceAll(string(event), "_", " "), ref.Status)
+	}
+
+	note = strings.TrimSpace(note)
+	if t.RequiresNote && note == "" {
+		return models.AchievementRef{}, 400, "note is required"
+	}
+
+	user := c.Locals("user").(models.Users)
+
+	next := t.Apply(ref, workflow.Change{
+		By:           user.ID,
+		DelegationID: delegationID,
+		Note:         note,
+		At:           time.Now(),
+	})
+
+	write := repository.TransitionWrite{
+		Ref:          next,
+		From:         ref.Status,
+		By:           user.ID,
+		DelegationID: delegationID,
+		Note:         note,
+		CloseRound:   t.Effects&workflow.CloseRound != 0,
+	}
+	if stage != nil {
+		write.ApproveStage = &stage.ID
+	}
+	if t.Effects&workflow.OpenRound != 0 {
+		round, stages, err := w.prepareRound(c.Context(), next, user.ID)
+		if err != nil {
+			fmt.Println("ERROR prepare submission round:", err)
+			return models.AchievementRef{}, 500, "failed record submission round"
+		}
+		write.OpenRound, write.Stages = &round, stages
+	}
+
+	err := w.transitions.Apply(c.Context(), write)
+	if errors.Is(err, repository.ErrStatusChanged) || errors.Is(err, repository.ErrStageDecided) {
+		return models.AchievementRef{}, 409, err.Error()
+	}
+	if err != nil {
+		fmt.Println("ERROR apply transition:", err)
+		return models.AchievementRef{}, 500, "failed update status"
+	}
+
+	historyStatus, historyNote := next.Status, note
+	if t.Event == workflow.ApproveStage {
+		historyStatus, historyNote = "stage-approved", stage.Name
+		if note != "" {
+			historyNote += ": " + note
+		}
+	}
+
+	err = w.mongo.PushHistoryByHexID(c.Context(), ref.MongoAchievementID, historyStatus, user.ID.String(), historyNote)
+	if err != nil {
+		fmt.Println("MONGO HISTORY ERROR:", err)
+	}
+
+	return next, 0, ""
+}
+
+// prepareRound menyiapkan round baru saat submit: snapshot + perubahan
+// dibanding round sebelumnya, beserta stage chain yang cocok (nil kalau
+// cukup diverifikasi dosen wali seperti biasa).
+func (w *achievementWorkflow) prepareRound(
+	ctx context.Context,
+	ref models.AchievementRef,
+	by uuid.UUID,
+) (models.AchievementSubmission, []models.ApprovalStage, error) {
+	detail, err := w.mongo.FindByHexID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		return models.AchievementSubmission{}, nil, err
+	}
+
+	rounds, err := w.submissions.FindByAchievement(ctx, ref.ID)
+	if err != nil {
+		return models.AchievementSubmission{}, nil, err
+	}
+
+	snapshot := detail.Snapshot()
+
+	var changes []models.FieldChange
+	if len(rounds) > 0 {
+		changes = workflow.Diff(rounds[len(rounds)-1].Snapshot, snapshot)
+	}
+
+	round := models.AchievementSubmission{
+		ID:            uuid.New(),
+		AchievementID: ref.ID,
+		Snapshot:      snapshot,
+		Changes:       changes,
+		SubmittedBy:   by,
+		SubmittedAt:   ref.UpdatedAt,
+	}
+
+	chain, err := w.chains.Match(ctx, snapshot.Level, snapshot.Category)
+	if errors.Is(err, sql.ErrNoRows) {
+		return round, nil, nil
+	}
+	if err != nil {
+		return models.AchievementSubmission{}, nil, err
+	}
+
+	return round, chain.Stages, nil
+}
+
+// Submissions mengembalikan seluruh round prestasi :id untuk pemilik
+// maupun reviewer-nya.
+func (w *achievementWorkflow) Submissions(c *fiber.Ctx) ([]models.AchievementSubmission, int, string) {
+	ref, status, msg := authorizeAchievement(c, w.repo, w.policies, policy.ActionRead)
+	if status != 0 {
+		return nil, status, msg
+	}
+
+	rounds, err := w.submissions.FindByAchievement(c.Context(), ref.ID)
+	if err != nil {
+		return nil, 500, "failed load submissions"
+	}
+
+	if rounds == nil {
+		rounds = []models.AchievementSubmission{}
+	}
+	return rounds, 0, ""
+}
+
+// Approvals mengembalikan stage approval round terakhir (kosong kalau
+// prestasi tidak memakai approval chain).
+func (w *achievementWorkflow) Approvals(ctx context.Context, ref models.AchievementRef) ([]models.AchievementApproval, error) {
+	rounds, err := w.submissions.FindByAchievement(ctx, ref.ID)
+	if err != nil || len(rounds) == 0 {
+		return []models.AchievementApproval{}, err
+	}
+
+	return w.approvals.FindBySubmission(ctx, rounds[len(rounds)-1].ID)
+}
+
+// ChangesSinceLastReturn membandingkan isi prestasi sekarang dengan snapshot
+// round terakhir yang ditolak / diminta revisi. Round nil kalau belum pernah.
+func (w *achievementWorkflow) ChangesSinceLastReturn(
+	ctx context.Context,
+	ref models.AchievementRef,
+	detail models.AchievementDetail,
+) (*models.AchievementSubmission, []models.FieldChange, error) {
+	rounds, err := w.submissions.FindByAchievement(ctx, ref.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := len(rounds) - 1; i >= 0; i-- {
+		if rounds[i].Outcome != nil && workflow.Returned(*rounds[i].Outcome) {
+			return &rounds[i], workflow.Diff(rounds[i].Snapshot, detail.Snapshot()), nil
+		}
+	}
+
+	return nil, []models.FieldChange{}, nil
+}
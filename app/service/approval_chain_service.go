@@ -0,0 +1,177 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var approvalStageScopes = map[string]bool{
+	models.StageScopeAdvisor:    true,
+	models.StageScopeDepartment: true,
+	models.StageScopeGlobal:     true,
+}
+
+type ApprovalChainService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+}
+
+type approvalChainService struct {
+	repo        repository.ApprovalChainRepository
+	permissions repository.PermissionRepository
+}
+
+func NewApprovalChainService(
+	repo repository.ApprovalChainRepository,
+	permissions repository.PermissionRepository,
+) ApprovalChainService {
+	return &approvalChainService{repo, permissions}
+}
+
+func (s *approvalChainService) GetAll(c *fiber.Ctx) error {
+	chains, err := s.repo.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch approval chains")
+	}
+	return helper.Success(c, chains)
+}
+
+// parseChain membaca body create / update. Level dan category kosong
+// berarti chain berlaku untuk semua level / kategori.
+func (s *approvalChainService) parseChain(c *fiber.Ctx, id uuid.UUID) (models.ApprovalChain, int, string) {
+	var req struct {
+		Name     string `json:"name"`
+		Level    string `json:"level"`
+		Category string `json:"category"`
+		Stages   []struct {
+			Name       string `json:"name"`
+			Permission string `json:"permission"`
+			Scope      string `json:"scope"`
+		} `json:"stages"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return models.ApprovalChain{}, 400, "invalid body"
+	}
+
+	chain := models.ApprovalChain{
+		ID:   id,
+		Name: strings.TrimSpace(req.Name),
+	}
+	if chain.Name == "" {
+		return models.ApprovalChain{}, 400, "name is required"
+	}
+
+	if level := strings.TrimSpace(req.Level); level != "" {
+		chain.Level = &level
+	}
+	if category := strings.TrimSpace(req.Category); category != "" {
+		chain.Category = &category
+	}
+
+	if len(req.Stages) == 0 {
+		return models.ApprovalChain{}, 400, "at least one stage is required"
+	}
+
+	perms, err := s.permissions.FindAll(c.Context())
+	if err != nil {
+		return models.ApprovalChain{}, 500, "failed fetch permissions"
+	}
+	known := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		known[p.Name] = true
+	}
+
+	for i, st := range req.Stages {
+		stage := models.ApprovalStage{
+			ID:         uuid.New(),
+			Position:   i + 1,
+			Name:       strings.TrimSpace(st.Name),
+			Permission: st.Permission,
+			Scope:      st.Scope,
+		}
+
+		if stage.Name == "" {
+			return models.ApprovalChain{}, 400, "stage name is required"
+		}
+		if !known[stage.Permission] {
+			return models.ApprovalChain{}, 400, "unknown permission " + stage.Permission
+		}
+		if !approvalStageScopes[stage.Scope] {
+			return models.ApprovalChain{}, 400, "stage scope must be advisor, department or global"
+		}
+
+		chain.Stages = append(chain.Stages, stage)
+	}
+
+	return chain, 0, ""
+}
+
+func (s *approvalChainService) Create(c *fiber.Ctx) error {
+	chain, status, msg := s.parseChain(c, uuid.New())
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	err := s.repo.Create(c.Context(), chain)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "an approval chain for this level and category already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed create approval chain")
+	}
+
+	return helper.Success(c, chain)
+}
+
+// Update hanya berlaku untuk submit berikutnya; round yang sedang berjalan
+// tetap memakai stage saat disubmit.
+func (s *approvalChainService) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	chain, status, msg := s.parseChain(c, id)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	err = s.repo.Update(c.Context(), chain)
+	if errors.Is(err, sql.ErrNoRows) {
+		return helper.Error(c, 404, "approval chain not found")
+	}
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "an approval chain for this level and category already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed update approval chain")
+	}
+
+	return helper.Success(c, chain)
+}
+
+func (s *approvalChainService) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	err = s.repo.Delete(c.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return helper.Error(c, 404, "approval chain not found")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed delete approval chain")
+	}
+
+	return helper.Success(c, "approval chain deleted")
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/app/workflow"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ApprovalService melayani reviewer stage approval chain (mis. kepala
+// departemen, kemahasiswaan) yang bukan dosen wali mahasiswanya.
+type ApprovalService interface {
+	GetQueue(c *fiber.Ctx) error
+	GetDetail(c *fiber.Ctx) error
+	Approve(c *fiber.Ctx) error
+	Reject(c *fiber.Ctx) error
+	RequestRevision(c *fiber.Ctx) error
+}
+
+type approvalService struct {
+	repo      repository.AchievementRepository
+	mongo     repository.MongoAchievementRepository
+	approvals repository.ApprovalRepository
+	policies  *policy.Resolver
+	workflow  *achievementWorkflow
+}
+
+func NewApprovalService(
+	repo repository.AchievementRepository,
+	mongo repository.MongoAchievementRepository,
+	submissions repository.SubmissionRepository,
+	approvals repository.ApprovalRepository,
+	chains repository.ApprovalChainRepository,
+	transitions repository.TransitionRepository,
+	policies *policy.Resolver,
+) ApprovalService {
+	return &approvalService{
+		repo:      repo,
+		mongo:     mongo,
+		approvals: approvals,
+		policies:  policies,
+		workflow:  &achievementWorkflow{repo, mongo, submissions, approvals, chains, transitions, policies},
+	}
+}
+
+// GetQueue menampilkan prestasi yang stage aktifnya bisa disetujui actor.
+// ?stage=<nama stage> untuk memfilter satu antrian.
+func (s *approvalService) GetQueue(c *fiber.Ctx) error {
+	actor, err := s.policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return helper.Error(c, 500, "failed to check permissions")
+	}
+
+	var perms []string
+	if !actor.IsSuperAdmin() {
+		perms = []string{}
+		for name, ok := range actor.Permissions {
+			if ok {
+				perms = append(perms, name)
+			}
+		}
+	}
+
+	items, err := s.approvals.FindQueue(c.Context(), perms, c.Query("stage"))
+	if err != nil {
+		return helper.Error(c, 500, "failed load approval queue")
+	}
+
+	// permission saja belum cukup; cakupan stage (dosen wali / departemen)
+	// dicek lewat policy untuk tiap prestasi
+	queue := []models.ApprovalQueueItem{}
+	for _, item := range items {
+		res, err := s.policies.Achievement(c.Context(), item.Achievement)
+		if err != nil {
+			continue
+		}
+		if policy.Can(actor, policy.ActionVerify, res) {
+			queue = append(queue, item)
+		}
+	}
+
+	return helper.Success(c, queue)
+}
+
+func (s *approvalService) GetDetail(c *fiber.Ctx) error {
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	detail, err := s.mongo.FindByHexID(c.Context(), ref.MongoAchievementID)
+	if err != nil {
+		return helper.Error(c, 500, "failed load mongo detail")
+	}
+
+	approvals, err := s.workflow.Approvals(c.Context(), ref)
+	if err != nil {
+		return helper.Error(c, 500, "failed load approval stages")
+	}
+
+	return helper.Success(c, fiber.Map{
+		"reference": ref,
+		"detail":    detail,
+		"approvals": approvals,
+	})
+}
+
+// Approve menyetujui stage aktif; prestasi baru verified kalau stage
+// tersebut adalah stage terakhir.
+func (s *approvalService) Approve(c *fiber.Ctx) error {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	ref, status, msg := s.workflow.Transition(c, workflow.Verify, req.Note)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, ref.Status)
+}
+
+func (s *approvalService) Reject(c *fiber.Ctx) error {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	ref, status, msg := s.workflow.Transition(c, workflow.Reject, req.Note)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, ref.Status)
+}
+
+func (s *approvalService) RequestRevision(c *fiber.Ctx) error {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	ref, status, msg := s.workflow.Transition(c, workflow.RequestRevision, req.Note)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, ref.Status)
+}
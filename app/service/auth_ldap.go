@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+)
+
+type ldapConfig struct {
+	URL          string
+	StartTLS     bool
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	UserFilter   string
+	EmailAttr    string
+	NameAttr     string
+	GroupAttr    string
+	UUIDAttr     string
+	GroupRoles   map[string]string
+	DefaultRole  string
+}
+
+func loadLDAPConfig() ldapConfig {
+	cfg := ldapConfig{
+		URL:          os.Getenv("LDAP_URL"),
+		StartTLS:     os.Getenv("LDAP_START_TLS") == "true",
+		BindDN:       os.Getenv("LDAP_BIND_DN"),
+		BindPassword: os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:       os.Getenv("LDAP_BASE_DN"),
+		UserFilter:   envOr("LDAP_USER_FILTER", "(uid=%s)"),
+		EmailAttr:    envOr("LDAP_ATTR_EMAIL", "mail"),
+		NameAttr:     envOr("LDAP_ATTR_NAME", "cn"),
+		GroupAttr:    envOr("LDAP_ATTR_GROUPS", "memberOf"),
+		UUIDAttr:     envOr("LDAP_ATTR_UUID", "entryUUID"),
+		DefaultRole:  os.Getenv("LDAP_DEFAULT_ROLE"),
+		GroupRoles:   make(map[string]string),
+	}
+
+	// kunci mapping = CN grup (case-insensitive), contoh "dosen=Dosen Wali"
+	for group, role := range parseRoleMap(os.Getenv("LDAP_GROUP_ROLE_MAP")) {
+		cfg.GroupRoles[strings.ToLower(group)] = role
+	}
+
+	return cfg
+}
+
+func envOr(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// ldapConn adalah bagian dari *ldap.Conn yang dipakai provider, supaya
+// bisa diganti stand-in in-process saat test.
+type ldapConn interface {
+	Bind(username string, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+type ldapDialer func(cfg ldapConfig) (ldapConn, error)
+
+func dialLDAP(cfg ldapConfig) (ldapConn, error) {
+	conn, err := ldap.DialURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.StartTLS {
+		host := strings.TrimPrefix(strings.TrimPrefix(cfg.URL, "ldap://"), "ldaps://")
+		host = strings.Split(host, ":")[0]
+		if err := conn.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+type ldapAuthProvider struct {
+	cfg    ldapConfig
+	dial   ldapDialer
+	users  repository.UserRepository
+	roles  repository.RoleRepository
+	hasher helper.PasswordHasher
+}
+
+func NewLDAPAuthProvider(
+	cfg ldapConfig,
+	dial ldapDialer,
+	users repository.UserRepository,
+	roles repository.RoleRepository,
+	hasher helper.PasswordHasher,
+) AuthProvider {
+	return &ldapAuthProvider{cfg, dial, users, roles, hasher}
+}
+
+func (p *ldapAuthProvider) Name() string {
+	return "ldap"
+}
+
+// Authenticate: bind akun service -> cari DN user -> bind sebagai user
+// -> sinkronkan atribut ke tabel users.
+func (p *ldapAuthProvider) Authenticate(ctx context.Context, username string, password string) (models.Users, error) {
+	if password == "" {
+		// bind tanpa password = unauthenticated bind yang selalu "berhasil"
+		return models.Users{}, errInvalidCredentials
+	}
+
+	conn, err := p.dial(p.cfg)
+	if err != nil {
+		return models.Users{}, fmt.Errorf("ldap dial: %w", err)
+	}
+	defer conn.Close()
+
+	if p.cfg.BindDN != "" {
+		if err := conn.Bind(p.cfg.BindDN, p.cfg.BindPassword); err != nil {
+			return models.Users{}, fmt.Errorf("ldap service bind: %w", err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		p.cfg.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(p.cfg.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"dn", p.cfg.UUIDAttr, p.cfg.EmailAttr, p.cfg.NameAttr, p.cfg.GroupAttr},
+		nil,
+	))
+	if err != nil {
+		return models.Users{}, fmt.Errorf("ldap search: %w", err)
+	}
+
+	if len(result.Entries) == 0 {
+		return models.Users{}, errUnknownUser
+	}
+	if len(result.Entries) > 1 {
+		return models.Users{}, errors.New("ldap search returned multiple entries")
+	}
+
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return models.Users{}, errInvalidCredentials
+		}
+		return models.Users{}, fmt.Errorf("ldap user bind: %w", err)
+	}
+
+	return p.sync(ctx, username, entry)
+}
+
+func (p *ldapAuthProvider) sync(ctx context.Context, username string, entry *ldap.Entry) (models.Users, error) {
+	user := models.Users{
+		ID:       uuid.New(),
+		Username: username,
+		Email:    entry.GetAttributeValue(p.cfg.EmailAttr),
+		FullName: entry.GetAttributeValue(p.cfg.NameAttr),
+		IsActive: true,
+	}
+
+	if user.FullName == "" {
+		user.FullName = username
+	}
+
+	// tanpa mapping grup RoleID dibiarkan kosong: role user lama dipertahankan
+	roleName := p.roleFor(entry.GetAttributeValues(p.cfg.GroupAttr))
+	if roleName != "" {
+		role, err := p.roles.FindByName(ctx, roleName)
+		if err != nil {
+			return models.Users{}, fmt.Errorf("role %q: %w", roleName, err)
+		}
+		user.RoleID = role.ID
+	}
+
+	// password lokal acak; akun LDAP hanya bisa login lewat directory
+	randomPassword, err := helper.RandomToken(32)
+	if err != nil {
+		return models.Users{}, err
+	}
+	hash, err := p.hasher.Hash(randomPassword)
+	if err != nil {
+		return models.Users{}, err
+	}
+	user.PasswordHash = hash
+
+	// entryUUID tetap walaupun DN berubah (pindah OU); DN hanya cadangan
+	subject := entry.GetAttributeValue(p.cfg.UUIDAttr)
+	if subject == "" {
+		subject = strings.ToLower(entry.DN)
+	}
+
+	return p.users.UpsertFromDirectory(ctx, user, models.UserIdentity{
+		ID:       uuid.New(),
+		Provider: "ldap",
+		Subject:  subject,
+		Email:    user.Email,
+	})
+}
+
+// roleFor mencocokkan CN dari setiap grup (memberOf) dengan GroupRoles.
+func (p *ldapAuthProvider) roleFor(groups []string) string {
+	for _, group := range groups {
+		name := group
+		if dn, err := ldap.ParseDN(group); err == nil && len(dn.RDNs) > 0 && len(dn.RDNs[0].Attributes) > 0 {
+			name = dn.RDNs[0].Attributes[0].Value
+		}
+
+		if role, ok := p.cfg.GroupRoles[strings.ToLower(name)]; ok {
+			return role
+		}
+	}
+
+	return p.cfg.DefaultRole
+}
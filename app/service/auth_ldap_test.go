@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"uas/app/models"
+	"uas/app/repository"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+)
+
+// fakeDirectory adalah stand-in LDAP in-process di balik seam ldapConn.
+type fakeDirectory struct {
+	serviceDN       string
+	servicePassword string
+	entries         []fakeEntry
+
+	dials    int
+	filters  []string
+	boundDNs []string
+}
+
+type fakeEntry struct {
+	dn       string
+	uid      string
+	password string
+	attrs    map[string][]string
+}
+
+func (d *fakeDirectory) dial(cfg ldapConfig) (ldapConn, error) {
+	d.dials++
+	return &fakeConn{dir: d}, nil
+}
+
+type fakeConn struct {
+	dir *fakeDirectory
+}
+
+func (c *fakeConn) Bind(username string, password string) error {
+	c.dir.boundDNs = append(c.dir.boundDNs, username)
+
+	if username == c.dir.serviceDN && password == c.dir.servicePassword {
+		return nil
+	}
+	for _, e := range c.dir.entries {
+		if e.dn == username && e.password == password {
+			return nil
+		}
+	}
+
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+}
+
+func (c *fakeConn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.dir.filters = append(c.dir.filters, req.Filter)
+
+	result := &ldap.SearchResult{}
+	for _, e := range c.dir.entries {
+		if req.Filter != fmt.Sprintf("(uid=%s)", e.uid) {
+			continue
+		}
+		entry := ldap.NewEntry(e.dn, e.attrs)
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+// fakeDirectoryUsers hanya mengimplementasikan method yang dipakai provider LDAP.
+type fakeDirectoryUsers struct {
+	repository.UserRepository
+
+	err      error
+	upserted []models.Users
+	linked   []models.UserIdentity
+}
+
+func (r *fakeDirectoryUsers) UpsertFromDirectory(ctx context.Context, u models.Users, i models.UserIdentity) (models.Users, error) {
+	if r.err != nil {
+		return models.Users{}, r.err
+	}
+	r.upserted = append(r.upserted, u)
+	r.linked = append(r.linked, i)
+	return u, nil
+}
+
+type fakeRoles struct {
+	repository.RoleRepository
+
+	byName map[string]models.Role
+}
+
+func (r *fakeRoles) FindByName(ctx context.Context, name string) (models.Role, error) {
+	role, ok := r.byName[name]
+	if !ok {
+		return models.Role{}, errors.New("role not found")
+	}
+	return role, nil
+}
+
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) { return "hashed:" + password, nil }
+func (plainHasher) Verify(hash string, password string) bool {
+	return hash == "hashed:"+password
+}
+func (plainHasher) NeedsRehash(hash string) bool { return false }
+
+func TestLDAPAuthenticate(t *testing.T) {
+	dosenRole := models.Role{ID: uuid.New(), Name: "Dosen Wali"}
+
+	newDirectory := func() *fakeDirectory {
+		return &fakeDirectory{
+			serviceDN:       "cn=svc,dc=kampus",
+			servicePassword: "svc-secret",
+			entries: []fakeEntry{
+				{
+					dn:       "uid=budi,ou=dosen,dc=kampus",
+					uid:      "budi",
+					password: "rahasia",
+					attrs: map[string][]string{
+						"entryUUID": {"6f1c7c1e-0000-4000-8000-000000000001"},
+						"mail":      {"budi@kampus.ac.id"},
+						"cn":        {"Budi Santoso"},
+						"memberOf":  {"cn=Dosen,ou=groups,dc=kampus"},
+					},
+				},
+				{
+					dn:       "uid=tamu,ou=tamu,dc=kampus",
+					uid:      "tamu",
+					password: "tamu123",
+					attrs: map[string][]string{
+						"mail": {"tamu@kampus.ac.id"},
+					},
+				},
+			},
+		}
+	}
+
+	cfg := ldapConfig{
+		BindDN:       "cn=svc,dc=kampus",
+		BindPassword: "svc-secret",
+		BaseDN:       "dc=kampus",
+		UserFilter:   "(uid=%s)",
+		UUIDAttr:     "entryUUID",
+		EmailAttr:    "mail",
+		NameAttr:     "cn",
+		GroupAttr:    "memberOf",
+		GroupRoles:   map[string]string{"dosen": "Dosen Wali"},
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		repoErr  error
+
+		wantErr     error
+		wantSubject string
+		wantRole    uuid.UUID
+		wantName    string
+	}{
+		{
+			name:        "mapped group links by entryUUID",
+			username:    "budi",
+			password:    "rahasia",
+			wantSubject: "6f1c7c1e-0000-4000-8000-000000000001",
+			wantRole:    dosenRole.ID,
+			wantName:    "Budi Santoso",
+		},
+		{
+			name:        "no entryUUID falls back to DN and keeps existing role",
+			username:    "tamu",
+			password:    "tamu123",
+			wantSubject: "uid=tamu,ou=tamu,dc=kampus",
+			wantRole:    uuid.Nil,
+			wantName:    "tamu",
+		},
+		{name: "wrong password", username: "budi", password: "salah", wantErr: errInvalidCredentials},
+		{name: "empty password never binds", username: "budi", password: "", wantErr: errInvalidCredentials},
+		{name: "unknown user", username: "siti", password: "x", wantErr: errUnknownUser},
+		{name: "filter injection is escaped", username: "*", password: "x", wantErr: errUnknownUser},
+		{
+			name:     "local account is not taken over",
+			username: "budi",
+			password: "rahasia",
+			repoErr:  repository.ErrLocalAccountExists,
+			wantErr:  repository.ErrLocalAccountExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newDirectory()
+			users := &fakeDirectoryUsers{err: tt.repoErr}
+			roles := &fakeRoles{byName: map[string]models.Role{dosenRole.Name: dosenRole}}
+
+			p := NewLDAPAuthProvider(cfg, dir.dial, users, roles, plainHasher{})
+
+			user, err := p.Authenticate(context.Background(), tt.username, tt.password)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if tt.password == "" && dir.dials != 0 {
+					t.Fatalf("dialed directory for empty password")
+				}
+				if tt.username == "*" && dir.filters[0] != `(uid=\2a)` {
+					t.Fatalf("filter = %q, want escaped", dir.filters[0])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+
+			if len(users.linked) != 1 {
+				t.Fatalf("UpsertFromDirectory called %d times", len(users.linked))
+			}
+			identity := users.linked[0]
+			if identity.Provider != "ldap" || identity.Subject != tt.wantSubject {
+				t.Fatalf("identity = %s/%s, want ldap/%s", identity.Provider, identity.Subject, tt.wantSubject)
+			}
+			if user.RoleID != tt.wantRole {
+				t.Fatalf("role = %s, want %s", user.RoleID, tt.wantRole)
+			}
+			if user.FullName != tt.wantName {
+				t.Fatalf("full name = %q, want %q", user.FullName, tt.wantName)
+			}
+			if user.PasswordHash == "" {
+				t.Fatalf("directory user without placeholder password hash")
+			}
+		})
+	}
+}
+
+func TestLDAPServiceBindFailure(t *testing.T) {
+	dir := &fakeDirectory{serviceDN: "cn=svc,dc=kampus", servicePassword: "svc-secret"}
+	cfg := ldapConfig{BindDN: "cn=svc,dc=kampus", BindPassword: "wrong", UserFilter: "(uid=%s)"}
+
+	p := NewLDAPAuthProvider(cfg, dir.dial, &fakeDirectoryUsers{}, &fakeRoles{}, plainHasher{})
+
+	_, err := p.Authenticate(context.Background(), "budi", "rahasia")
+	if err == nil || errors.Is(err, errInvalidCredentials) || errors.Is(err, errUnknownUser) {
+		t.Fatalf("err = %v, want service bind error", err)
+	}
+	if len(dir.filters) != 0 {
+		t.Fatalf("searched directory after failed service bind")
+	}
+}
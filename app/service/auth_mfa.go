@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"uas/app/models"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+// mfaChallenge mengembalikan nil kalau user boleh langsung login.
+// Selain itu dikembalikan challenge token untuk endpoint /auth/mfa/verify.
+func (s *authService) mfaChallenge(ctx context.Context, user models.Users) (fiber.Map, error) {
+	enabled := false
+
+	mfa, err := s.mfa.FindByUser(ctx, user.ID)
+	if err == nil {
+		enabled = mfa.Enabled
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	required := false
+	if !enabled {
+		role, err := s.roles.FindByID(ctx, user.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		required = role.MFARequired
+	}
+
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	claims := jwt.MapClaims{
+		"id":  user.ID.String(),
+		"jti": uuid.NewString(),
+		"exp": time.Now().Add(mfaTokenTTL).Unix(),
+	}
+
+	token, err := s.keys.Sign(helper.TokenMFA, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return fiber.Map{
+		"mfaRequired":           true,
+		"mfaEnrollmentRequired": !enabled,
+		"mfaToken":              token,
+	}, nil
+}
+
+func (s *authService) parseMFAToken(ctx context.Context, tokenStr string) (models.Users, jwt.MapClaims, error) {
+	claims, err := s.keys.Parse(helper.TokenMFA, tokenStr)
+	if err != nil {
+		return models.Users{}, nil, errors.New("invalid or expired MFA token")
+	}
+
+	jti, _ := claims["jti"].(string)
+	revoked, err := s.revocations.IsRevoked(ctx, jti)
+	if err != nil || revoked {
+		return models.Users{}, nil, errors.New("MFA token already used")
+	}
+
+	userID, _ := claims["id"].(string)
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return models.Users{}, nil, errors.New("user not found")
+	}
+
+	return user, claims, nil
+}
+
+// startEnrollment membuat secret + recovery codes baru yang belum aktif
+// sampai dikonfirmasi dengan kode TOTP pertama.
+func (s *authService) startEnrollment(ctx context.Context, user models.Users) (fiber.Map, error) {
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.mfa.SavePending(ctx, user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !saved {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := helper.RandomToken(8)
+		if err != nil {
+			return nil, err
+		}
+		code = strings.ToLower(code)
+		codes = append(codes, code)
+		hashes = append(hashes, helper.HashToken(code))
+	}
+
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "UAS Prestasi"
+	}
+
+	return fiber.Map{
+		"secret":        secret,
+		"otpauthUri":    helper.TOTPURI(issuer, user.Username, secret),
+		"recoveryCodes": codes,
+	}, nil
+}
+
+var errMFAAlreadyEnabled = errors.New("MFA already enabled")
+
+// checkMFACode menerima kode TOTP atau salah satu recovery code.
+func (s *authService) checkMFACode(ctx context.Context, mfa models.UserMFA, code string, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		if !mfa.Enabled {
+			return false, nil
+		}
+		return s.mfa.UseRecoveryCode(ctx, mfa.UserID, helper.HashToken(strings.ToLower(strings.TrimSpace(recoveryCode))))
+	}
+
+	step, ok := helper.VerifyTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return s.mfa.MarkStepUsed(ctx, mfa.UserID, step)
+}
+
+func (s *authService) EnrollMFA(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	result, err := s.startEnrollment(c.Context(), user)
+	if errors.Is(err, errMFAAlreadyEnabled) {
+		return helper.Error(c, 409, err.Error())
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed to start MFA enrollment")
+	}
+
+	return helper.Success(c, result)
+}
+
+func (s *authService) ConfirmMFA(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	mfa, err := s.mfa.FindByUser(c.Context(), user.ID)
+	if err != nil {
+		return helper.Error(c, 400, "MFA enrollment not started")
+	}
+
+	if mfa.Enabled {
+		return helper.Error(c, 409, errMFAAlreadyEnabled.Error())
+	}
+
+	ok, err := s.checkMFACode(c.Context(), mfa, req.Code, "")
+	if err != nil {
+		return helper.Error(c, 500, "failed to verify code")
+	}
+	if !ok {
+		return helper.Error(c, 401, "invalid MFA code")
+	}
+
+	if err := s.mfa.Enable(c.Context(), user.ID); err != nil {
+		return helper.Error(c, 500, "failed to enable MFA")
+	}
+
+	s.recordEvent(c, user.ID, "mfa_enabled", "TOTP enabled")
+
+	return helper.Success(c, "MFA enabled")
+}
+
+func (s *authService) DisableMFA(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	var req struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	role, err := s.roles.FindByID(c.Context(), user.RoleID)
+	if err != nil {
+		return helper.Error(c, 500, "failed to load role")
+	}
+
+	if role.MFARequired {
+		return helper.Error(c, 403, "MFA is mandatory for role "+role.Name)
+	}
+
+	mfa, err := s.mfa.FindByUser(c.Context(), user.ID)
+	if err != nil || !mfa.Enabled {
+		return helper.Error(c, 400, "MFA is not enabled")
+	}
+
+	ok, err := s.checkMFACode(c.Context(), mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		return helper.Error(c, 500, "failed to verify code")
+	}
+	if !ok {
+		return helper.Error(c, 401, "invalid MFA code")
+	}
+
+	if err := s.mfa.Disable(c.Context(), user.ID); err != nil {
+		return helper.Error(c, 500, "failed to disable MFA")
+	}
+
+	s.recordEvent(c, user.ID, "mfa_disabled", "TOTP disabled")
+
+	return helper.Success(c, "MFA disabled")
+}
+
+// ChallengeEnrollMFA dipakai user yang role-nya mewajibkan MFA tetapi
+// belum pernah enroll: enrollment dilakukan dengan mfaToken dari login.
+func (s *authService) ChallengeEnrollMFA(c *fiber.Ctx) error {
+	var req struct {
+		MFAToken string `json:"mfaToken"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	user, _, err := s.parseMFAToken(c.Context(), req.MFAToken)
+	if err != nil {
+		return helper.Error(c, 401, err.Error())
+	}
+
+	result, err := s.startEnrollment(c.Context(), user)
+	if errors.Is(err, errMFAAlreadyEnabled) {
+		return helper.Error(c, 409, err.Error())
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed to start MFA enrollment")
+	}
+
+	return helper.Success(c, result)
+}
+
+// VerifyMFA menukar mfaToken + kode TOTP (atau recovery code) dengan token pair.
+// Kalau MFA user masih pending, kode yang valid sekaligus mengaktifkannya.
+func (s *authService) VerifyMFA(c *fiber.Ctx) error {
+	var req struct {
+		MFAToken     string `json:"mfaToken"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	user, claims, err := s.parseMFAToken(c.Context(), req.MFAToken)
+	if err != nil {
+		return helper.Error(c, 401, err.Error())
+	}
+
+	if err := s.guard.CheckUser(user); err != nil {
+		return s.loginBlocked(c, err)
+	}
+
+	mfa, err := s.mfa.FindByUser(c.Context(), user.ID)
+	if err != nil {
+		return helper.Error(c, 400, "MFA enrollment required")
+	}
+
+	ok, err := s.checkMFACode(c.Context(), mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		return helper.Error(c, 500, "failed to verify code")
+	}
+
+	if !ok {
+		locked, err := s.guard.Failed(c.Context(), user.Username, c.IP(), &user)
+		if err != nil {
+			fmt.Println("ERROR record login attempt:", err)
+		}
+		if locked {
+			s.recordEvent(c, user.ID, "account_locked", "locked after repeated invalid MFA codes")
+		}
+		return helper.Error(c, 401, "invalid MFA code")
+	}
+
+	if !mfa.Enabled {
+		if err := s.mfa.Enable(c.Context(), user.ID); err != nil {
+			return helper.Error(c, 500, "failed to enable MFA")
+		}
+		s.recordEvent(c, user.ID, "mfa_enabled", "TOTP enabled during login")
+	}
+
+	if req.RecoveryCode != "" {
+		s.recordEvent(c, user.ID, "mfa_recovery_code_used", "login with recovery code")
+	}
+
+	// mfaToken hanya boleh ditukar sekali
+	jti, _ := claims["jti"].(string)
+	exp := time.Unix(int64(claims["exp"].(float64)), 0)
+	if err := s.revocations.Revoke(c.Context(), jti, exp); err != nil {
+		return helper.Error(c, 500, "failed to complete login")
+	}
+
+	if err := s.guard.Succeeded(c.Context(), user.Username, c.IP(), user); err != nil {
+		fmt.Println("ERROR record login attempt:", err)
+	}
+
+	return s.completeLogin(c, user)
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"uas/app/models"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+var (
+	errOIDCNoAccount      = errors.New("no local account for this identity")
+	errOIDCPrivilegedLink = errors.New("this account must be linked to SSO by an administrator")
+)
+
+// oidcPrivilegedPermissions: pemegang permission ini tidak di-link otomatis
+// lewat email IdP.
+var oidcPrivilegedPermissions = map[string]bool{
+	"user:manage": true,
+}
+
+// OIDCLogin mengarahkan browser ke identity provider kampus
+// (authorization code + PKCE).
+func (s *authService) OIDCLogin(c *fiber.Ctx) error {
+	if s.oidc == nil {
+		return helper.Error(c, 404, "SSO is not configured")
+	}
+
+	state, err1 := helper.RandomToken(24)
+	nonce, err2 := helper.RandomToken(24)
+	verifier, err3 := helper.RandomToken(48)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return helper.Error(c, 500, "failed to start SSO login")
+	}
+
+	exp := time.Now().Add(oidcStateTTL)
+
+	// state, nonce dan verifier disimpan di cookie bertanda tangan supaya
+	// callback bisa diproses instance mana pun
+	stateToken, err := s.keys.Sign(helper.TokenOIDCState, jwt.MapClaims{
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      exp.Unix(),
+	})
+	if err != nil {
+		return helper.Error(c, 500, "failed to start SSO login")
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(c.Context(), state, nonce, verifier)
+	if err != nil {
+		fmt.Println("ERROR OIDC:", err)
+		return helper.Error(c, 502, "identity provider unavailable")
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateToken,
+		Path:     "/app/auth/oidc",
+		Expires:  exp,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+func (s *authService) OIDCCallback(c *fiber.Ctx) error {
+	if s.oidc == nil {
+		return helper.Error(c, 404, "SSO is not configured")
+	}
+
+	if e := c.Query("error"); e != "" {
+		return helper.Error(c, 401, "SSO login failed: "+e)
+	}
+
+	stateClaims, err := s.keys.Parse(helper.TokenOIDCState, c.Cookies(oidcStateCookie))
+	c.ClearCookie(oidcStateCookie)
+	if err != nil {
+		return helper.Error(c, 400, "SSO session expired, please try again")
+	}
+
+	state, _ := stateClaims["state"].(string)
+	if state == "" || c.Query("state") != state {
+		return helper.Error(c, 400, "invalid SSO state")
+	}
+
+	nonce, _ := stateClaims["nonce"].(string)
+	verifier, _ := stateClaims["verifier"].(string)
+
+	idClaims, err := s.oidc.Exchange(c.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		fmt.Println("ERROR OIDC exchange:", err)
+		return helper.Error(c, 401, "SSO login failed")
+	}
+
+	user, err := s.resolveOIDCUser(c.Context(), idClaims)
+	if errors.Is(err, errOIDCNoAccount) || errors.Is(err, errOIDCPrivilegedLink) {
+		return helper.Error(c, 403, err.Error())
+	}
+	if err != nil {
+		fmt.Println("ERROR OIDC user mapping:", err)
+		return helper.Error(c, 500, "failed to map SSO account")
+	}
+
+	if !user.IsActive {
+		return helper.Error(c, 403, "account is not active")
+	}
+
+	s.recordEvent(c, user.ID, "sso_login", "login via "+s.oidc.Issuer)
+
+	challenge, err := s.mfaChallenge(c.Context(), user)
+	if err != nil {
+		return helper.Error(c, 500, "failed to check MFA")
+	}
+
+	if challenge != nil {
+		return helper.Success(c, challenge)
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// resolveOIDCUser mencari user lokal dari subject provider, lalu dari email
+// terverifikasi (kecuali akun admin), dan terakhir membuat user baru kalau OIDC_JIT_PROVISION aktif.
+func (s *authService) resolveOIDCUser(ctx context.Context, claims jwt.MapClaims) (models.Users, error) {
+	provider := s.oidc.Issuer
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	emailVerified, _ := claims["email_verified"].(bool)
+
+	userID, err := s.identities.FindUserID(ctx, provider, subject)
+	if err == nil {
+		return s.users.FindByID(ctx, userID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.Users{}, err
+	}
+
+	identity := models.UserIdentity{
+		ID:       uuid.New(),
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}
+
+	if email != "" && emailVerified {
+		if user, err := s.users.FindByEmail(ctx, email); err == nil {
+			// akun admin tidak pernah di-link otomatis: siapa pun yang bisa
+			// mengatur email di IdP tidak boleh mengambil alih akun admin
+			privileged, err := s.isPrivileged(ctx, user)
+			if err != nil {
+				return models.Users{}, err
+			}
+			if privileged {
+				return models.Users{}, errOIDCPrivilegedLink
+			}
+
+			identity.UserID = user.ID
+			if err := s.identities.Link(ctx, identity); err != nil {
+				return models.Users{}, err
+			}
+			return user, nil
+		}
+	}
+
+	if os.Getenv("OIDC_JIT_PROVISION") != "true" || email == "" || !emailVerified {
+		return models.Users{}, errOIDCNoAccount
+	}
+
+	roleName := oidcRoleFromClaims(claims)
+	if roleName == "" {
+		return models.Users{}, errOIDCNoAccount
+	}
+
+	role, err := s.roles.FindByName(ctx, roleName)
+	if err != nil {
+		return models.Users{}, fmt.Errorf("role %q: %w", roleName, err)
+	}
+
+	// akun SSO tidak punya password lokal yang bisa ditebak
+	randomPassword, err := helper.RandomToken(32)
+	if err != nil {
+		return models.Users{}, err
+	}
+	hash, err := s.hasher.Hash(randomPassword)
+	if err != nil {
+		return models.Users{}, err
+	}
+
+	username, _ := claims["preferred_username"].(string)
+	if username == "" {
+		username = strings.Split(email, "@")[0]
+	}
+
+	fullName, _ := claims["name"].(string)
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := models.Users{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: hash,
+		FullName:     fullName,
+		RoleID:       role.ID,
+		IsActive:     true,
+	}
+
+	if err := s.identities.ProvisionUser(ctx, user, identity); err != nil {
+		return models.Users{}, err
+	}
+
+	return user, nil
+}
+
+func (s *authService) isPrivileged(ctx context.Context, user models.Users) (bool, error) {
+	perms, err := s.permissions.FindByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range perms {
+		if oidcPrivilegedPermissions[p.Name] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// oidcRoleFromClaims memetakan nilai claim OIDC_ROLE_CLAIM (default "groups")
+// ke nama role lewat OIDC_ROLE_MAP, contoh: "mahasiswa=Mahasiswa,dosen=Dosen Wali".
+// Kalau tidak ada yang cocok dipakai OIDC_DEFAULT_ROLE.
+func oidcRoleFromClaims(claims jwt.MapClaims) string {
+	claimName := os.Getenv("OIDC_ROLE_CLAIM")
+	if claimName == "" {
+		claimName = "groups"
+	}
+
+	var values []string
+	switch v := claims[claimName].(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	mapping := parseRoleMap(os.Getenv("OIDC_ROLE_MAP"))
+	for _, v := range values {
+		if role, ok := mapping[v]; ok {
+			return role
+		}
+	}
+
+	return os.Getenv("OIDC_DEFAULT_ROLE")
+}
+
+// parseRoleMap membaca format "kunci=Nama Role,kunci2=Nama Role 2".
+func parseRoleMap(raw string) map[string]string {
+	mapping := make(map[string]string)
+
+	for _, pair := range strings.Split(raw, ",") {
+		key, role, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		mapping[strings.TrimSpace(key)] = strings.TrimSpace(role)
+	}
+
+	return mapping
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+)
+
+var (
+	errUnknownUser        = errors.New("user not found")
+	errInvalidCredentials = errors.New("invalid credentials")
+)
+
+// AuthProvider memverifikasi username/password ke satu sumber akun.
+// errUnknownUser / errInvalidCredentials berarti provider berikutnya boleh dicoba.
+type AuthProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, username string, password string) (models.Users, error)
+}
+
+// NewAuthProvidersFromEnv menyusun provider sesuai urutan AUTH_PROVIDERS
+// (default "local"), contoh: "local,ldap".
+func NewAuthProvidersFromEnv(
+	users repository.UserRepository,
+	roles repository.RoleRepository,
+	hasher helper.PasswordHasher,
+) []AuthProvider {
+	names := os.Getenv("AUTH_PROVIDERS")
+	if names == "" {
+		names = "local"
+	}
+
+	var providers []AuthProvider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "local":
+			providers = append(providers, NewLocalAuthProvider(users, hasher))
+		case "ldap":
+			providers = append(providers, NewLDAPAuthProvider(loadLDAPConfig(), dialLDAP, users, roles, hasher))
+		}
+	}
+
+	return providers
+}
+
+type localAuthProvider struct {
+	users  repository.UserRepository
+	hasher helper.PasswordHasher
+}
+
+func NewLocalAuthProvider(users repository.UserRepository, hasher helper.PasswordHasher) AuthProvider {
+	return &localAuthProvider{users, hasher}
+}
+
+func (p *localAuthProvider) Name() string {
+	return "local"
+}
+
+func (p *localAuthProvider) Authenticate(ctx context.Context, username string, password string) (models.Users, error) {
+	user, err := p.users.FindByUsername(ctx, username)
+	if err != nil {
+		return models.Users{}, errUnknownUser
+	}
+
+	if !p.hasher.Verify(user.PasswordHash, password) {
+		return models.Users{}, errInvalidCredentials
+	}
+
+	return user, nil
+}
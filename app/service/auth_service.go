@@ -1,18 +1,29 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"os"
-	"strings"
+	"strconv"
 	"time"
 
+	"uas/app/models"
 	"uas/app/repository"
 	"uas/helper"
 	"uas/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
+)
+
+const (
+	accessTokenTTL   = 24 * time.Hour
+	refreshTokenTTL  = 7 * 24 * time.Hour
+	passwordResetTTL = 30 * time.Minute
 )
 
 type AuthService interface {
@@ -20,14 +31,74 @@ type AuthService interface {
 	RefreshHandler(c *fiber.Ctx) error
 	ProfileHandler(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
+	ListSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	RevokeAllSessions(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	EnrollMFA(c *fiber.Ctx) error
+	ConfirmMFA(c *fiber.Ctx) error
+	DisableMFA(c *fiber.Ctx) error
+	ChallengeEnrollMFA(c *fiber.Ctx) error
+	VerifyMFA(c *fiber.Ctx) error
+	JWKSHandler(c *fiber.Ctx) error
+	OIDCLogin(c *fiber.Ctx) error
+	OIDCCallback(c *fiber.Ctx) error
 }
 
 type authService struct {
-	users repository.UserRepository
+	users         repository.UserRepository
+	revocations   middleware.TokenRevocationStore
+	refreshTokens repository.RefreshTokenRepository
+	events        repository.SecurityEventRepository
+	resets        repository.PasswordResetRepository
+	mailer        helper.Mailer
+	guard         *loginGuard
+	mfa           repository.MFARepository
+	roles         repository.RoleRepository
+	permissions   repository.PermissionRepository
+	keys          *helper.KeySet
+	oidc          *helper.OIDCProvider
+	identities    repository.IdentityRepository
+	providers     []AuthProvider
+	hasher        helper.PasswordHasher
 }
 
-func NewAuthService(users repository.UserRepository) AuthService {
-	return &authService{users}
+func NewAuthService(
+	users repository.UserRepository,
+	revocations middleware.TokenRevocationStore,
+	refreshTokens repository.RefreshTokenRepository,
+	events repository.SecurityEventRepository,
+	resets repository.PasswordResetRepository,
+	mailer helper.Mailer,
+	attempts repository.LoginAttemptRepository,
+	mfa repository.MFARepository,
+	roles repository.RoleRepository,
+	permissions repository.PermissionRepository,
+	keys *helper.KeySet,
+	oidc *helper.OIDCProvider,
+	identities repository.IdentityRepository,
+	providers []AuthProvider,
+	hasher helper.PasswordHasher,
+) AuthService {
+	return &authService{
+		users:         users,
+		revocations:   revocations,
+		refreshTokens: refreshTokens,
+		events:        events,
+		resets:        resets,
+		mailer:        mailer,
+		guard:         newLoginGuard(users, attempts),
+		mfa:           mfa,
+		roles:         roles,
+		permissions:   permissions,
+		keys:          keys,
+		oidc:          oidc,
+		identities:    identities,
+		providers:     providers,
+		hasher:        hasher,
+	}
 }
 
 func (s *authService) LoginHandler(c *fiber.Ctx) error {
@@ -40,8 +111,39 @@ func (s *authService) LoginHandler(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid request body")
 	}
 
-	user, err := s.users.FindByUsername(c.Context(), req.Username)
+	ip := c.IP()
+
+	if err := s.guard.CheckIP(c.Context(), ip); err != nil {
+		return s.loginBlocked(c, err)
+	}
+
+	// user lokal (kalau ada) dipakai untuk lockout sebelum provider dicoba;
+	// user LDAP yang belum pernah login belum punya baris di tabel users
+	var known *models.Users
+	if existing, err := s.users.FindByUsername(c.Context(), req.Username); err == nil {
+		if err := s.guard.CheckUser(existing); err != nil {
+			return s.loginBlocked(c, err)
+		}
+
+		if !existing.IsActive {
+			return helper.Error(c, 403, "account is not active")
+		}
+
+		known = &existing
+	}
+
+	user, provider, err := s.authenticate(c.Context(), req.Username, req.Password)
 	if err != nil {
+		locked, err := s.guard.Failed(c.Context(), req.Username, ip, known)
+		if err != nil {
+			fmt.Println("ERROR record login attempt:", err)
+		}
+
+		if locked {
+			s.recordEvent(c, known.ID, "account_locked",
+				fmt.Sprintf("locked after %d failed login attempts", s.guard.maxAttempts))
+		}
+
 		return helper.Error(c, 401, "invalid credentials")
 	}
 
@@ -49,48 +151,94 @@ func (s *authService) LoginHandler(c *fiber.Ctx) error {
 		return helper.Error(c, 403, "account is not active")
 	}
 
-	// cek password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return helper.Error(c, 401, "invalid credentials")
+	// hash lama (bcrypt / parameter lemah) diganti selagi password plaintext tersedia;
+	// hanya untuk akun lokal, hash akun LDAP adalah placeholder acak
+	if provider == "local" && s.hasher.NeedsRehash(user.PasswordHash) {
+		s.rehashPassword(c.Context(), user, req.Password)
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "DEFAULT_SECRET"
+	// user dengan MFA aktif / role yang mewajibkan MFA harus lewat langkah kedua
+	challenge, err := s.mfaChallenge(c.Context(), user)
+	if err != nil {
+		fmt.Println("ERROR MFA challenge:", err)
+		return helper.Error(c, 500, "failed to check MFA")
 	}
 
-	accessClaims := jwt.MapClaims{
-		"id":     user.ID.String(),
-		"roleId": user.RoleID.String(),
-		"exp":    time.Now().Add(time.Hour * 24).Unix(),
+	// counter kegagalan baru di-reset setelah langkah MFA berhasil; kalau
+	// di-reset di sini, tebakan TOTP bisa diselingi login password ulang
+	// tanpa pernah terkena lockout
+	if challenge != nil {
+		return helper.Success(c, challenge)
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	signedAccess, err := accessToken.SignedString([]byte(secret))
+	if err := s.guard.Succeeded(c.Context(), req.Username, ip, user); err != nil {
+		fmt.Println("ERROR record login attempt:", err)
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// authenticate mencoba setiap provider sesuai urutan konfigurasi dan
+// mengembalikan nama provider yang berhasil.
+func (s *authService) authenticate(ctx context.Context, username string, password string) (models.Users, string, error) {
+	for _, p := range s.providers {
+		user, err := p.Authenticate(ctx, username, password)
+		if err == nil {
+			return user, p.Name(), nil
+		}
+
+		if !errors.Is(err, errUnknownUser) && !errors.Is(err, errInvalidCredentials) {
+			fmt.Println("ERROR auth provider", p.Name()+":", err)
+		}
+	}
+
+	return models.Users{}, "", errInvalidCredentials
+}
+
+// rehashPassword tidak menaikkan token_version: password-nya sendiri tidak berubah.
+func (s *authService) rehashPassword(ctx context.Context, user models.Users, password string) {
+	hash, err := s.hasher.Hash(password)
 	if err != nil {
-		return helper.Error(c, 500, "failed to generate access token")
+		fmt.Println("ERROR rehash password:", err)
+		return
 	}
 
-	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecret == "" {
-		refreshSecret = "DEFAULT_REFRESH_SECRET"
+	if err := s.users.ReplacePasswordHash(ctx, user.ID.String(), user.PasswordHash, hash); err != nil {
+		fmt.Println("ERROR rehash password:", err)
 	}
+}
 
-	refreshClaims := jwt.MapClaims{
-		"id":  user.ID.String(),
-		"exp": time.Now().Add(7 * 24 * time.Hour).Unix(),
+// completeLogin membuka token family (session) baru lalu menerbitkan token pair.
+func (s *authService) completeLogin(c *fiber.Ctx, user models.Users) error {
+	family := models.RefreshTokenFamily{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		IP:        c.IP(),
+		UserAgent: c.Get("User-Agent"),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.refreshTokens.CreateFamily(c.Context(), family); err != nil {
+		return helper.Error(c, 500, "failed to create session")
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	signedRefresh, err := refreshToken.SignedString([]byte(refreshSecret))
+	tokens, err := s.issueTokens(c.Context(), user, family.ID)
 	if err != nil {
-		return helper.Error(c, 500, "failed to generate refresh token")
+		return helper.Error(c, 500, err.Error())
 	}
 
-	return helper.Success(c, fiber.Map{
-		"accessToken":  signedAccess,
-		"refreshToken": signedRefresh,
-	})
+	return helper.Success(c, tokens)
+}
+
+func (s *authService) loginBlocked(c *fiber.Ctx, err error) error {
+	blocked, ok := err.(*loginBlockedError)
+	if !ok {
+		fmt.Println("ERROR login guard:", err)
+		return helper.Error(c, 500, "failed to check login attempts")
+	}
+
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	return helper.Error(c, blocked.Status, blocked.Message)
 }
 
 func (s *authService) RefreshHandler(c *fiber.Ctx) error {
@@ -105,43 +253,60 @@ func (s *authService) RefreshHandler(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid request body")
 	}
 
-	fmt.Println("Input RefreshToken:", req.RefreshToken)
-
 	if req.RefreshToken == "" {
 		fmt.Println("ERROR: Token kosong")
 		return helper.Error(c, 400, "missing refresh token")
 	}
 
-	// load refresh secret
-	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecret == "" {
-		refreshSecret = "DEFAULT_REFRESH_SECRET"
-	}
-
-	token, err := jwt.Parse(req.RefreshToken, func(t *jwt.Token) (interface{}, error) {
-		return []byte(refreshSecret), nil
-	})
-
+	claims, err := s.keys.Parse(helper.TokenRefresh, req.RefreshToken)
 	if err != nil {
 		fmt.Println("JWT Parse Error:", err)
 		return helper.Error(c, 401, "invalid or expired refresh token")
 	}
 
-	if !token.Valid {
-		fmt.Println("Token INVALID")
+	userID, _ := claims["id"].(string)
+	jtiStr, _ := claims["jti"].(string)
+	familyStr, _ := claims["fid"].(string)
+
+	jti, errJTI := uuid.Parse(jtiStr)
+	familyID, errFID := uuid.Parse(familyStr)
+	if userID == "" || errJTI != nil || errFID != nil {
+		fmt.Println("ERROR: Missing refresh token claims")
+		return helper.Error(c, 401, "invalid refresh token format")
+	}
+
+	stored, err := s.refreshTokens.FindByJTI(c.Context(), jti)
+	if err != nil || stored.FamilyID != familyID || stored.UserID.String() != userID {
+		fmt.Println("ERROR: refresh token tidak dikenal:", err)
 		return helper.Error(c, 401, "invalid refresh token")
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	fmt.Println("Claims:", claims)
+	family, err := s.refreshTokens.FindFamilyByID(c.Context(), familyID)
+	if err != nil {
+		return helper.Error(c, 401, "invalid refresh token")
+	}
 
-	userID, ok := claims["id"].(string)
-	if !ok {
-		fmt.Println("ERROR: Missing `id` claim")
-		return helper.Error(c, 401, "invalid refresh token format")
+	if family.RevokedAt != nil {
+		return helper.Error(c, 401, "refresh token revoked")
 	}
 
-	fmt.Println("UserID dari token:", userID)
+	fresh, err := s.refreshTokens.MarkUsed(c.Context(), jti)
+	if err != nil {
+		fmt.Println("ERROR MarkUsed:", err)
+		return helper.Error(c, 500, "failed to rotate refresh token")
+	}
+
+	if !fresh {
+		// token yang sudah dipakai datang lagi: anggap dicuri, matikan seluruh family
+		if err := s.revokeFamily(c.Context(), familyID, "reuse_detected"); err != nil {
+			fmt.Println("ERROR revoke family:", err)
+		}
+
+		s.recordEvent(c, stored.UserID, "refresh_token_reuse",
+			fmt.Sprintf("refresh token %s reused, family %s revoked", jti, familyID))
+
+		return helper.Error(c, 401, "refresh token already used")
+	}
 
 	user, err := s.users.FindByID(c.Context(), userID)
 	if err != nil {
@@ -149,70 +314,347 @@ func (s *authService) RefreshHandler(c *fiber.Ctx) error {
 		return helper.Error(c, 401, "user not found")
 	}
 
-	fmt.Println("User ditemukan:", user.Username)
+	// user dinonaktifkan / ganti role / ganti password sejak token ini terbit
+	if !user.IsActive || !helper.TokenVersionMatches(claims, user.TokenVersion) {
+		if err := s.revokeFamily(c.Context(), familyID, "token_version_changed"); err != nil {
+			fmt.Println("ERROR revoke family:", err)
+		}
+		return helper.Error(c, 401, "session is no longer valid, please login again")
+	}
+
+	if err := s.refreshTokens.TouchFamily(c.Context(), familyID, c.IP(), c.Get("User-Agent")); err != nil {
+		fmt.Println("ERROR TouchFamily:", err)
+	}
+
+	tokens, err := s.issueTokens(c.Context(), user, familyID)
+	if err != nil {
+		fmt.Println("ERROR ISSUE TOKEN:", err)
+		return helper.Error(c, 500, err.Error())
+	}
+
+	fmt.Println("=== END DEBUG REFRESH TOKEN ===")
+
+	return helper.Success(c, tokens)
+}
+
+func (s *authService) ProfileHandler(c *fiber.Ctx) error {
+	user := c.Locals("user")
+	if user == nil {
+		return helper.Error(c, 500, "failed to load user profile")
+	}
+
+	return helper.Success(c, user)
+}
+
+func (s *authService) Logout(c *fiber.Ctx) error {
+	// request dengan API key tidak punya session
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return helper.Error(c, 400, "no session to logout")
+	}
+
+	jti := claims["jti"].(string)
+	expUnix := int64(claims["exp"].(float64))
+	exp := time.Unix(expUnix, 0)
+
+	if err := s.revocations.Revoke(c.Context(), jti, exp); err != nil {
+		return helper.Error(c, 500, "failed to logout")
+	}
+
+	if sid, ok := claims["sid"].(string); ok {
+		if familyID, err := uuid.Parse(sid); err == nil {
+			if err := s.revokeFamily(c.Context(), familyID, "logout"); err != nil {
+				return helper.Error(c, 500, "failed to logout")
+			}
+		}
+	}
+
+	return helper.Success(c, "logged out")
+}
+
+// JWKSHandler mempublikasikan public key supaya layanan lain bisa
+// memverifikasi token yang kita terbitkan.
+func (s *authService) JWKSHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(s.keys.JWKS())
+}
+
+func (s *authService) ListSessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+	claims, _ := c.Locals("claims").(jwt.MapClaims)
+	currentSID, _ := claims["sid"].(string)
+
+	families, err := s.refreshTokens.FindActiveFamiliesByUser(c.Context(), user.ID)
+	if err != nil {
+		return helper.Error(c, 500, "failed load sessions")
+	}
+
+	results := make([]fiber.Map, 0, len(families))
+	for _, f := range families {
+		results = append(results, fiber.Map{
+			"id":         f.ID,
+			"ip":         f.IP,
+			"userAgent":  f.UserAgent,
+			"createdAt":  f.CreatedAt,
+			"lastUsedAt": f.LastUsedAt,
+			"current":    f.ID.String() == currentSID,
+		})
+	}
+
+	return helper.Success(c, results)
+}
+
+func (s *authService) RevokeSession(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	familyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	family, err := s.refreshTokens.FindFamilyByID(c.Context(), familyID)
+	if err != nil || family.UserID != user.ID {
+		return helper.Error(c, 404, "session not found")
+	}
+
+	if err := s.revokeFamily(c.Context(), familyID, "session_revoked"); err != nil {
+		return helper.Error(c, 500, "failed to revoke session")
+	}
+
+	return helper.Success(c, "session revoked")
+}
+
+// RevokeAllSessions = logout dari semua perangkat, termasuk session saat ini.
+func (s *authService) RevokeAllSessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	if err := s.revokeUserSessions(c.Context(), user.ID, "logout_all"); err != nil {
+		return helper.Error(c, 500, "failed to revoke sessions")
+	}
+
+	return helper.Success(c, "all sessions revoked")
+}
+
+func (s *authService) ChangePassword(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	var req struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	if !s.hasher.Verify(user.PasswordHash, req.CurrentPassword) {
+		return helper.Error(c, 401, "current password is incorrect")
+	}
+
+	if err := helper.ValidatePassword(req.NewPassword); err != nil {
+		return helper.Error(c, 400, err.Error())
+	}
+
+	if err := s.setPassword(c.Context(), user.ID, req.NewPassword, "password_changed"); err != nil {
+		return helper.Error(c, 500, "failed to change password")
+	}
+
+	s.recordEvent(c, user.ID, "password_changed", "password changed by user")
+
+	return helper.Success(c, "password changed, please login again")
+}
+
+// ForgotPassword selalu mengembalikan sukses supaya tidak membocorkan
+// email mana yang terdaftar.
+func (s *authService) ForgotPassword(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	const response = "if the email is registered, a reset link has been sent"
+
+	user, err := s.users.FindByEmail(c.Context(), req.Email)
+	if err != nil || !user.IsActive {
+		return helper.Success(c, response)
+	}
+
+	rawToken, err := helper.RandomToken(32)
+	if err != nil {
+		return helper.Error(c, 500, "failed to generate reset token")
+	}
+
+	reset := models.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+
+	if err := s.resets.Create(c.Context(), reset); err != nil {
+		return helper.Error(c, 500, "failed to create reset token")
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nGunakan link berikut untuk mengganti password (berlaku %d menit):\n%s?token=%s\n\nAbaikan email ini jika kamu tidak memintanya.",
+		user.FullName,
+		int(passwordResetTTL.Minutes()),
+		os.Getenv("PASSWORD_RESET_URL"),
+		rawToken,
+	)
+
+	if err := s.mailer.Send(c.Context(), user.Email, "Reset password", body); err != nil {
+		fmt.Println("ERROR SEND MAIL:", err)
+	}
+
+	s.recordEvent(c, user.ID, "password_reset_requested", "reset token issued")
+
+	return helper.Success(c, response)
+}
+
+func (s *authService) ResetPassword(c *fiber.Ctx) error {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	if err := helper.ValidatePassword(req.NewPassword); err != nil {
+		return helper.Error(c, 400, err.Error())
+	}
+
+	tokenHash := helper.HashToken(req.Token)
+
+	reset, err := s.resets.FindByHash(c.Context(), tokenHash)
+	if err != nil {
+		return helper.Error(c, 400, "invalid or expired reset token")
+	}
+
+	ok, err := s.resets.MarkUsed(c.Context(), tokenHash)
+	if err != nil {
+		return helper.Error(c, 500, "failed to reset password")
+	}
+
+	if !ok {
+		return helper.Error(c, 400, "invalid or expired reset token")
+	}
+
+	if err := s.setPassword(c.Context(), reset.UserID, req.NewPassword, "password_reset"); err != nil {
+		return helper.Error(c, 500, "failed to reset password")
+	}
+
+	s.recordEvent(c, reset.UserID, "password_reset", "password reset via email token")
+
+	return helper.Success(c, "password has been reset, please login again")
+}
+
+// setPassword menyimpan hash baru lalu mencabut semua session milik user
+// sehingga access dan refresh token lama tidak berlaku lagi.
+func (s *authService) setPassword(ctx context.Context, userID uuid.UUID, password string, reason string) error {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, userID.String(), hash); err != nil {
+		return err
+	}
+
+	return s.revokeUserSessions(ctx, userID, reason)
+}
+
+// issueTokens membuat access token dan refresh token baru dalam family yang sama.
+func (s *authService) issueTokens(ctx context.Context, user models.Users, familyID uuid.UUID) (fiber.Map, error) {
+	now := time.Now()
 
-	// access token baru
 	accessClaims := jwt.MapClaims{
 		"id":     user.ID.String(),
 		"roleId": user.RoleID.String(),
-		"exp":    time.Now().Add(24 * time.Hour).Unix(),
+		"jti":    uuid.NewString(),
+		"sid":    familyID.String(),
+		"ver":    user.TokenVersion,
+		"exp":    now.Add(accessTokenTTL).Unix(),
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-
-	signedAccess, err := accessToken.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	signedAccess, err := s.keys.Sign(helper.TokenAccess, accessClaims)
 	if err != nil {
-		fmt.Println("ERROR ACCESS TOKEN:", err)
-		return helper.Error(c, 500, "failed to generate access token")
+		return nil, fmt.Errorf("failed to generate access token")
 	}
 
-	fmt.Println("Access Token Baru:", signedAccess)
+	refresh := models.RefreshToken{
+		JTI:       uuid.New(),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(refreshTokenTTL),
+	}
+
+	if err := s.refreshTokens.Create(ctx, refresh); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token")
+	}
 
-	// generate refresh token
 	refreshClaims := jwt.MapClaims{
 		"id":  user.ID.String(),
-		"exp": time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 hari
+		"jti": refresh.JTI.String(),
+		"fid": familyID.String(),
+		"ver": user.TokenVersion,
+		"exp": refresh.ExpiresAt.Unix(),
 	}
 
-	newRefresh := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-
-	signedRefresh, err := newRefresh.SignedString([]byte(refreshSecret))
+	signedRefresh, err := s.keys.Sign(helper.TokenRefresh, refreshClaims)
 	if err != nil {
-		fmt.Println("ERROR REFRESH TOKEN:", err)
-		return helper.Error(c, 500, "failed to generate refresh token")
+		return nil, fmt.Errorf("failed to generate refresh token")
 	}
 
-	fmt.Println("Refresh Token Baru:", signedRefresh)
-	fmt.Println("=== END DEBUG REFRESH TOKEN ===")
-
-	return helper.Success(c, fiber.Map{
+	return fiber.Map{
 		"accessToken":  signedAccess,
 		"refreshToken": signedRefresh,
-	})
+	}, nil
 }
 
-func (s *authService) ProfileHandler(c *fiber.Ctx) error {
-	user := c.Locals("user")
-	if user == nil {
-		return helper.Error(c, 500, "failed to load user profile")
+// revokeFamily mencabut refresh family sekaligus semua access token yang
+// diterbitkan dari family tersebut (lewat claim sid).
+func (s *authService) revokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	if err := s.refreshTokens.RevokeFamily(ctx, familyID, reason); err != nil {
+		return err
 	}
 
-	return helper.Success(c, user)
+	return s.revocations.Revoke(ctx, familyID.String(), time.Now().Add(accessTokenTTL))
 }
 
-func (s *authService) Logout(c *fiber.Ctx) error {
-	authHeader := c.Get("Authorization")
-	if authHeader == "" {
-		return helper.Error(c, 400, "missing token")
+func (s *authService) revokeUserSessions(ctx context.Context, userID uuid.UUID, reason string) error {
+	families, err := s.refreshTokens.FindActiveFamiliesByUser(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	tokenStr := strings.Replace(authHeader, "Bearer ", "", 1)
+	for _, f := range families {
+		if err := s.revokeFamily(ctx, f.ID, reason); err != nil {
+			return err
+		}
+	}
 
-	claims := c.Locals("claims").(jwt.MapClaims)
-	expUnix := int64(claims["exp"].(float64))
-	exp := time.Unix(expUnix, 0)
+	return nil
+}
 
-	middleware.BlacklistToken(tokenStr, exp)
+func (s *authService) recordEvent(c *fiber.Ctx, userID uuid.UUID, eventType string, detail string) {
+	log.Printf("[SECURITY] %s user=%s ip=%s: %s", eventType, userID, c.IP(), detail)
 
-	return helper.Success(c, "logged out")
+	err := s.events.Record(c.Context(), models.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &userID,
+		EventType: eventType,
+		IP:        c.IP(),
+		UserAgent: c.Get("User-Agent"),
+		Detail:    detail,
+	})
+	if err != nil {
+		log.Println("failed record security event:", err)
+	}
 }
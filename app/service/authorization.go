@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// authorizeAchievement memuat prestasi :id lalu mengecek policy untuk
+// action tersebut. Kalau status != 0, request harus dihentikan dengan
+// kode dan pesan yang dikembalikan.
+func authorizeAchievement(
+	c *fiber.Ctx,
+	repo repository.AchievementRepository,
+	policies *policy.Resolver,
+	action policy.Action,
+) (models.AchievementRef, int, string) {
+	ref, _, status, msg := authorizeReview(c, repo, policies, action)
+	return ref, status, msg
+}
+
+// authorizeReview sama seperti authorizeAchievement, tapi juga mengembalikan
+// id delegasi kalau akses actor hanya didapat lewat delegasi verifikasi.
+func authorizeReview(
+	c *fiber.Ctx,
+	repo repository.AchievementRepository,
+	policies *policy.Resolver,
+	action policy.Action,
+) (models.AchievementRef, *uuid.UUID, int, string) {
+	refID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.AchievementRef{}, nil, 400, "invalid id"
+	}
+
+	return authorizeReviewByID(c, repo, policies, action, refID)
+}
+
+// authorizeReviewByID sama seperti authorizeReview untuk prestasi refID
+// (mis. item pada operasi bulk) alih-alih parameter :id.
+func authorizeReviewByID(
+	c *fiber.Ctx,
+	repo repository.AchievementRepository,
+	policies *policy.Resolver,
+	action policy.Action,
+	refID uuid.UUID,
+) (models.AchievementRef, *uuid.UUID, int, string) {
+	actor, err := policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return models.AchievementRef{}, nil, 500, "failed to check permissions"
+	}
+
+	return authorizeReviewAs(c.Context(), repo, policies, actor, action, refID)
+}
+
+// authorizeReviewAs sama seperti authorizeReviewByID untuk actor yang sudah
+// di-resolve, supaya operasi bulk tidak me-resolve actor per item.
+func authorizeReviewAs(
+	ctx context.Context,
+	repo repository.AchievementRepository,
+	policies *policy.Resolver,
+	actor policy.Actor,
+	action policy.Action,
+	refID uuid.UUID,
+) (models.AchievementRef, *uuid.UUID, int, string) {
+	ref, res, status, msg := authorizeActor(ctx, repo, policies, actor, action, refID)
+	if status != 0 {
+		return models.AchievementRef{}, nil, status, msg
+	}
+
+	if delegationID, ok := policy.ViaDelegation(actor, res); ok {
+		return ref, &delegationID, 0, ""
+	}
+
+	return ref, nil, 0, ""
+}
+
+// authorizeActor memuat prestasi refID dan mengecek policy untuk actor yang
+// sudah di-resolve. Resource ikut dikembalikan untuk pengecekan lanjutan.
+func authorizeActor(
+	ctx context.Context,
+	repo repository.AchievementRepository,
+	policies *policy.Resolver,
+	actor policy.Actor,
+	action policy.Action,
+	refID uuid.UUID,
+) (models.AchievementRef, policy.Resource, int, string) {
+	// hanya data yang memang tidak ada yang menjadi 404; error database
+	// lain tetap 500 supaya tidak terbaca sebagai resource hilang
+	ref, err := repo.FindByID(ctx, refID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AchievementRef{}, policy.Resource{}, 404, "achievement not found"
+	}
+	if err != nil {
+		fmt.Println("ERROR load achievement:", err)
+		return models.AchievementRef{}, policy.Resource{}, 500, "failed load achievement"
+	}
+
+	res, err := policies.Achievement(ctx, ref)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AchievementRef{}, policy.Resource{}, 404, "student not found"
+	}
+	if err != nil {
+		fmt.Println("ERROR resolve achievement policy:", err)
+		return models.AchievementRef{}, policy.Resource{}, 500, "failed to check permissions"
+	}
+
+	if !policy.Can(actor, action, res) {
+		return models.AchievementRef{}, policy.Resource{}, 403, "forbidden"
+	}
+
+	return ref, res, 0, ""
+}
+
+// authorizeStudent sama seperti authorizeAchievement untuk data mahasiswa :id.
+func authorizeStudent(
+	c *fiber.Ctx,
+	repo repository.StudentRepository,
+	policies *policy.Resolver,
+	action policy.Action,
+) (models.Student, int, string) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.Student{}, 400, "invalid id"
+	}
+
+	student, err := repo.FindByID(c.Context(), id)
+	if err != nil {
+		return models.Student{}, 404, "mahasiswa tidak ditemukan"
+	}
+
+	actor, err := policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return models.Student{}, 500, "failed to check permissions"
+	}
+
+	if !policy.Can(actor, action, policies.Student(c.Context(), student)) {
+		return models.Student{}, 403, "forbidden"
+	}
+
+	return student, 0, ""
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxCommentLength = 5000
+
+// field prestasi yang boleh dirujuk komentar
+var commentFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"category":    true,
+	"level":       true,
+	"eventDate":   true,
+	"attachments": true,
+}
+
+// CommentService mengelola thread komentar antara mahasiswa pemilik prestasi
+// dan reviewer-nya (dosen wali, pengganti, reviewer stage, admin).
+type CommentService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetHistory(c *fiber.Ctx) error
+}
+
+type commentService struct {
+	repo     repository.AchievementRepository
+	mongo    repository.MongoAchievementRepository
+	comments repository.CommentRepository
+	policies *policy.Resolver
+}
+
+func NewCommentService(
+	repo repository.AchievementRepository,
+	mongo repository.MongoAchievementRepository,
+	comments repository.CommentRepository,
+	policies *policy.Resolver,
+) CommentService {
+	return &commentService{repo, mongo, comments, policies}
+}
+
+// access memuat prestasi :id, memastikan actor boleh berkomentar, dan
+// mengembalikan apakah actor juga reviewer (boleh melihat komentar internal).
+func (s *commentService) access(c *fiber.Ctx) (models.AchievementRef, bool, int, string) {
+	refID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.AchievementRef{}, false, 400, "invalid id"
+	}
+
+	actor, err := s.policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return models.AchievementRef{}, false, 500, "failed to check permissions"
+	}
+
+	ref, res, status, msg := authorizeActor(c.Context(), s.repo, s.policies, actor, policy.ActionComment, refID)
+	if status != 0 {
+		return models.AchievementRef{}, false, status, msg
+	}
+
+	return ref, policy.Can(actor, policy.ActionCommentInternal, res), 0, ""
+}
+
+// comment memuat komentar :commentId milik prestasi ref. Komentar internal
+// diperlakukan seperti tidak ada bagi mahasiswa.
+func (s *commentService) comment(c *fiber.Ctx, ref models.AchievementRef, internal bool) (models.AchievementComment, int, string) {
+	id, err := uuid.Parse(c.Params("commentId"))
+	if err != nil {
+		return models.AchievementComment{}, 400, "invalid comment id"
+	}
+
+	cm, err := s.comments.FindByID(c.Context(), id)
+	if err != nil || cm.AchievementID != ref.ID || (cm.Internal && !internal) {
+		return models.AchievementComment{}, 404, "comment not found"
+	}
+
+	return cm, 0, ""
+}
+
+func (s *commentService) GetAll(c *fiber.Ctx) error {
+	ref, internal, status, msg := s.access(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	list, err := s.comments.FindByAchievement(c.Context(), ref.ID, internal)
+	if err != nil {
+		return helper.Error(c, 500, "failed load comments")
+	}
+
+	return helper.Success(c, commentThreads(list))
+}
+
+// commentThreads menyusun komentar (urut waktu) menjadi thread. Balasan
+// yang induknya tidak ikut terbaca ditampilkan sebagai thread sendiri.
+func commentThreads(list []models.AchievementComment) []models.AchievementComment {
+	known := make(map[uuid.UUID]bool, len(list))
+	for _, cm := range list {
+		known[cm.ID] = true
+	}
+
+	children := map[uuid.UUID][]models.AchievementComment{}
+	roots := []models.AchievementComment{}
+	for _, cm := range list {
+		if cm.ParentID != nil && known[*cm.ParentID] {
+			children[*cm.ParentID] = append(children[*cm.ParentID], cm)
+		} else {
+			roots = append(roots, cm)
+		}
+	}
+
+	var build func(nodes []models.AchievementComment) []models.AchievementComment
+	build = func(nodes []models.AchievementComment) []models.AchievementComment {
+		for i := range nodes {
+			nodes[i].Replies = build(children[nodes[i].ID])
+		}
+		if nodes == nil {
+			return []models.AchievementComment{}
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+func (s *commentService) Create(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	ref, canInternal, status, msg := s.access(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	var req struct {
+		Body       string `json:"body"`
+		ParentID   string `json:"parentId"`
+		Internal   bool   `json:"internal"`
+		Field      string `json:"field"`
+		Attachment string `json:"attachment"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		return helper.Error(c, 400, "body is required")
+	}
+	if len(req.Body) > maxCommentLength {
+		return helper.Error(c, 400, fmt.Sprintf("body must be at most %d characters", maxCommentLength))
+	}
+
+	if req.Internal && !canInternal {
+		return helper.Error(c, 403, "only reviewers can post internal comments")
+	}
+
+	now := time.Now()
+	cm := models.AchievementComment{
+		ID:            uuid.New(),
+		AchievementID: ref.ID,
+		AuthorID:      user.ID,
+		Body:          req.Body,
+		Internal:      req.Internal,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if req.ParentID != "" {
+		parentID, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			return helper.Error(c, 400, "invalid parentId")
+		}
+
+		parent, err := s.comments.FindByID(c.Context(), parentID)
+		if err != nil || parent.AchievementID != ref.ID || (parent.Internal && !canInternal) {
+			return helper.Error(c, 404, "parent comment not found")
+		}
+
+		// balasan untuk komentar internal tetap internal
+		cm.ParentID = &parent.ID
+		cm.Internal = cm.Internal || parent.Internal
+	}
+
+	if req.Field != "" {
+		if !commentFields[req.Field] {
+			return helper.Error(c, 400, "unknown field "+req.Field)
+		}
+		cm.Field = &req.Field
+	}
+
+	if req.Attachment != "" {
+		detail, err := s.mongo.FindByHexID(c.Context(), ref.MongoAchievementID)
+		if err != nil {
+			return helper.Error(c, 500, "failed load mongo detail")
+		}
+		if !containsString(detail.Attachments, req.Attachment) {
+			return helper.Error(c, 400, "attachment not found")
+		}
+		cm.Attachment = &req.Attachment
+	}
+
+	if err := s.comments.Create(c.Context(), cm); err != nil {
+		fmt.Println("ERROR create comment:", err)
+		return helper.Error(c, 500, "failed create comment")
+	}
+
+	created, err := s.comments.FindByID(c.Context(), cm.ID)
+	if err != nil {
+		return helper.Error(c, 500, "failed load comment")
+	}
+	created.Replies = []models.AchievementComment{}
+
+	return helper.Success(c, created)
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Update dan Delete hanya untuk penulis komentar; isi lamanya tetap
+// tersimpan dan bisa dilihat lewat GetHistory.
+func (s *commentService) Update(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	ref, internal, status, msg := s.access(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	cm, status, msg := s.comment(c, ref, internal)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	if cm.AuthorID != user.ID {
+		return helper.Error(c, 403, "only the author can edit this comment")
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		return helper.Error(c, 400, "body is required")
+	}
+	if len(req.Body) > maxCommentLength {
+		return helper.Error(c, 400, fmt.Sprintf("body must be at most %d characters", maxCommentLength))
+	}
+
+	err := s.comments.Update(c.Context(), cm.ID, req.Body, user.ID, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return helper.Error(c, 409, "comment has been deleted")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed update comment")
+	}
+
+	return helper.Success(c, "comment updated")
+}
+
+func (s *commentService) Delete(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	ref, internal, status, msg := s.access(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	cm, status, msg := s.comment(c, ref, internal)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	if cm.AuthorID != user.ID {
+		return helper.Error(c, 403, "only the author can delete this comment")
+	}
+
+	err := s.comments.Delete(c.Context(), cm.ID, user.ID, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return helper.Error(c, 409, "comment has already been deleted")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed delete comment")
+	}
+
+	return helper.Success(c, "comment deleted")
+}
+
+// GetHistory: isi lama komentar (termasuk yang sudah dihapus) hanya untuk
+// penulis dan reviewer; peserta lain cukup melihat kapan diubah.
+func (s *commentService) GetHistory(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	ref, internal, status, msg := s.access(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	cm, status, msg := s.comment(c, ref, internal)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	revisions, err := s.comments.FindRevisions(c.Context(), cm.ID)
+	if err != nil {
+		return helper.Error(c, 500, "failed load comment history")
+	}
+
+	if cm.AuthorID != user.ID && !internal {
+		for i := range revisions {
+			revisions[i].Body = ""
+		}
+	}
+
+	return helper.Success(c, revisions)
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"uas/app/models"
+	"uas/app/policy"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// delegasi verifikasi dibatasi supaya tidak jadi pengganti dosen wali permanen
+const maxDelegationDuration = 90 * 24 * time.Hour
+
+type DelegationService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Revoke(c *fiber.Ctx) error
+}
+
+type delegationService struct {
+	repo      repository.DelegationRepository
+	lecturers repository.LecturerRepository
+	policies  *policy.Resolver
+}
+
+func NewDelegationService(
+	repo repository.DelegationRepository,
+	lecturers repository.LecturerRepository,
+	policies *policy.Resolver,
+) DelegationService {
+	return &delegationService{repo, lecturers, policies}
+}
+
+// authorizeDelegator memuat dosen pemberi delegasi dan mengecek apakah
+// caller boleh mengatur delegasinya (dosen itu sendiri atau admin).
+// lecturerID kosong berarti dosen yang sedang login.
+func (s *delegationService) authorizeDelegator(c *fiber.Ctx, lecturerID string) (models.Lecturer, int, string) {
+	actor, err := s.policies.Actor(c)
+	if err != nil {
+		fmt.Println("ERROR resolve actor:", err)
+		return models.Lecturer{}, 500, "failed to check permissions"
+	}
+
+	id := actor.LecturerID
+	if lecturerID != "" {
+		if id, err = uuid.Parse(lecturerID); err != nil {
+			return models.Lecturer{}, 400, "invalid lecturer id"
+		}
+	}
+	if id == uuid.Nil {
+		return models.Lecturer{}, 400, "delegatorId is required"
+	}
+
+	lecturer, err := s.lecturers.FindByID(c.Context(), id)
+	if err != nil {
+		return models.Lecturer{}, 404, "dosen tidak ditemukan"
+	}
+
+	if !policy.Can(actor, policy.ActionDelegate, s.policies.Lecturer(lecturer)) {
+		return models.Lecturer{}, 403, "forbidden"
+	}
+
+	return lecturer, 0, ""
+}
+
+func (s *delegationService) GetAll(c *fiber.Ctx) error {
+	lecturer, status, msg := s.authorizeDelegator(c, c.Query("lecturerId"))
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	list, err := s.repo.FindByLecturer(c.Context(), lecturer.ID)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch delegations")
+	}
+
+	return helper.Success(c, list)
+}
+
+func (s *delegationService) Create(c *fiber.Ctx) error {
+	user := c.Locals("user").(models.Users)
+
+	var req struct {
+		DelegatorID string     `json:"delegatorId"`
+		DelegateID  string     `json:"delegateId"`
+		StartsAt    *time.Time `json:"startsAt"`
+		EndsAt      time.Time  `json:"endsAt"`
+		Reason      string     `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	delegator, status, msg := s.authorizeDelegator(c, req.DelegatorID)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	delegateID, err := uuid.Parse(req.DelegateID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid delegateId")
+	}
+	if delegateID == delegator.ID {
+		return helper.Error(c, 400, "cannot delegate to yourself")
+	}
+
+	delegate, err := s.lecturers.FindByID(c.Context(), delegateID)
+	if err != nil || !inOrgScope(c.Context(), delegate.OrgUnitID) {
+		return helper.Error(c, 404, "dosen pengganti tidak ditemukan")
+	}
+
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(now) {
+		return helper.Error(c, 400, "endsAt must be in the future and after startsAt")
+	}
+	if req.EndsAt.Sub(startsAt) > maxDelegationDuration {
+		return helper.Error(c, 400, fmt.Sprintf("a delegation may last at most %d days", int(maxDelegationDuration.Hours()/24)))
+	}
+
+	d := models.VerificationDelegation{
+		ID:          uuid.New(),
+		DelegatorID: delegator.ID,
+		DelegateID:  delegate.ID,
+		StartsAt:    startsAt,
+		EndsAt:      req.EndsAt,
+		Reason:      strings.TrimSpace(req.Reason),
+		CreatedBy:   user.ID,
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.Create(c.Context(), d); err != nil {
+		fmt.Println("ERROR create delegation:", err)
+		return helper.Error(c, 500, "failed create delegation")
+	}
+
+	return helper.Success(c, d)
+}
+
+func (s *delegationService) Revoke(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	d, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "delegation not found")
+	}
+
+	if _, status, msg := s.authorizeDelegator(c, d.DelegatorID.String()); status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	if d.RevokedAt != nil || !d.EndsAt.After(time.Now()) {
+		return helper.Error(c, 409, "delegation already ended")
+	}
+
+	if err := s.repo.Revoke(c.Context(), d.ID, time.Now()); err != nil {
+		return helper.Error(c, 500, "failed revoke delegation")
+	}
+
+	return helper.Success(c, "delegation revoked")
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"fmt"
-	"time"
+	"strings"
 	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
+	"uas/app/workflow"
 	"uas/helper"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,7 +18,13 @@ type LecturerAchievementService interface {
 	GetDetail(c *fiber.Ctx) error
 	Verify(c *fiber.Ctx) error
 	Reject(c *fiber.Ctx) error
+	RequestRevision(c *fiber.Ctx) error
+	BulkReview(c *fiber.Ctx) error
 	GetHistory(c *fiber.Ctx) error
+	GetSubmissions(c *fiber.Ctx) error
+	GetVersions(c *fiber.Ctx) error
+	GetVersion(c *fiber.Ctx) error
+	DiffVersions(c *fiber.Ctx) error
 }
 
 type lecturerAchievementService struct {
@@ -24,6 +32,9 @@ type lecturerAchievementService struct {
 	studentRepo  repository.StudentRepository
 	lecturerRepo repository.LecturerRepository
 	mongo        repository.MongoAchievementRepository
+	policies     *policy.Resolver
+	workflow     *achievementWorkflow
+	versions     *achievementVersions
 }
 
 func NewLecturerAchievementService(
@@ -31,8 +42,22 @@ func NewLecturerAchievementService(
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
 	mongo repository.MongoAchievementRepository,
+	submissions repository.SubmissionRepository,
+	versions repository.AchievementVersionRepository,
+	approvals repository.ApprovalRepository,
+	chains repository.ApprovalChainRepository,
+	transitions repository.TransitionRepository,
+	policies *policy.Resolver,
 ) LecturerAchievementService {
-	return &lecturerAchievementService{repo, studentRepo, lecturerRepo, mongo}
+	return &lecturerAchievementService{
+		repo:         repo,
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+		mongo:        mongo,
+		policies:     policies,
+		workflow:     &achievementWorkflow{repo, mongo, submissions, approvals, chains, transitions, policies},
+		versions:     &achievementVersions{repo, mongo, versions, policies},
+	}
 }
 
 func (s *lecturerAchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
@@ -47,36 +72,28 @@ func (s *lecturerAchievementService) GetAdviseeAchievements(c *fiber.Ctx) error
 	if err != nil {
 		return helper.Error(c, 500, "gagal memuat prestasi")
 	}
-	return helper.Success(c, list)
-}
-
-func (s *lecturerAchievementService) GetDetail(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
-	user := c.Locals("user").(models.Users)
 
-	lecturer, err := s.lecturerRepo.FindByUserID(
-		c.Context(),
-		user.ID.String(),
-	)
+	// ikut tampilkan mahasiswa bimbingan dosen yang sedang mendelegasikan verifikasinya
+	actor, err := s.policies.Actor(c)
 	if err != nil {
-		return helper.Error(c, 403, "bukan dosen")
+		return helper.Error(c, 500, "gagal memuat delegasi")
 	}
 
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "prestasi tidak ditemukan")
+	for delegatorID := range actor.Delegations {
+		delegated, err := s.repo.FindByAdvisor(c.Context(), delegatorID)
+		if err != nil {
+			return helper.Error(c, 500, "gagal memuat prestasi")
+		}
+		list = append(list, delegated...)
 	}
 
-	student, err := s.studentRepo.FindByID(
-		c.Context(),
-		ref.StudentID,
-	)
-	if err != nil {
-		return helper.Error(c, 404, "student tidak ditemukan")
-	}
+	return helper.Success(c, list)
+}
 
-	if student.AdvisorID != lecturer.ID {
-		return helper.Error(c, 403, "bukan mahasiswa bimbingan")
+func (s *lecturerAchievementService) GetDetail(c *fiber.Ctx) error {
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
 	detail, err := s.mongo.FindByHexID(
@@ -87,136 +104,219 @@ func (s *lecturerAchievementService) GetDetail(c *fiber.Ctx) error {
 		return helper.Error(c, 500, "gagal mengambil data dari mongo")
 	}
 
-	return helper.Success(c, detail)
-}
-
-func (s *lecturerAchievementService) Verify(c *fiber.Ctx) error {
-	refIDStr := c.Params("id")
-
-	refID, err := uuid.Parse(refIDStr)
+	// perubahan sejak terakhir kali ditolak / diminta revisi
+	lastReturn, changes, err := s.workflow.ChangesSinceLastReturn(c.Context(), ref, detail)
 	if err != nil {
-		return helper.Error(c, 400, "invalid id")
+		return helper.Error(c, 500, "gagal memuat riwayat submit")
 	}
 
-	user := c.Locals("user").(models.Users)
-
-	lecturer, err := s.lecturerRepo.FindByUserID(c.Context(), user.ID.String())
+	approvals, err := s.workflow.Approvals(c.Context(), ref)
 	if err != nil {
-		return helper.Error(c, 403, "bukan dosen")
+		return helper.Error(c, 500, "gagal memuat tahap persetujuan")
 	}
-	fmt.Println("Lecturer ID:", lecturer.ID)
 
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "prestasi tidak ditemukan")
+	return helper.Success(c, fiber.Map{
+		"reference":                 ref,
+		"detail":                    detail,
+		"approvals":                 approvals,
+		"lastRejection":             lastReturn,
+		"changesSinceLastRejection": changes,
+	})
+}
+
+func (s *lecturerAchievementService) Verify(c *fiber.Ctx) error {
+	ref, status, msg := s.workflow.Transition(c, workflow.Verify, "")
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	if ref.Status != "submitted" {
-		return helper.Error(c, 400, "hanya item tersubmit yang dapat diverifikasi")
+	fmt.Println("VERIFY SUCCESS")
+	return helper.Success(c, ref.Status)
+}
+
+func (s *lecturerAchievementService) Reject(c *fiber.Ctx) error {
+	var req struct {
+		Note string `json:"note"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
 	}
 
-	student, err := s.studentRepo.FindByID(c.Context(), ref.StudentID)
-	if err != nil {
-		return helper.Error(c, 404, "mahasiswa tidak ditemukan")
+	ref, status, msg := s.workflow.Transition(c, workflow.Reject, req.Note)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	fmt.Println("Student AdvisorID:", student.AdvisorID)
-	fmt.Println("Lecturer ID:", lecturer.ID)
+	return helper.Success(c, ref.Status)
+}
 
-	if student.AdvisorID != lecturer.ID {
-		return helper.Error(c, 403, "bukan mahasiswa bimbingan")
+// RequestRevision mengembalikan prestasi ke mahasiswa dengan feedback;
+// mahasiswa bisa mengubahnya lalu submit ulang.
+func (s *lecturerAchievementService) RequestRevision(c *fiber.Ctx) error {
+	var req struct {
+		Note string `json:"note"`
 	}
-	now := time.Now()
-
-	err = s.repo.UpdateStatusVerified(c.Context(), refID, user.ID, now)
-	if err != nil {
-		return helper.Error(c, 500, "gagal diverifikasi")
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
 	}
 
-	err = s.mongo.PushHistoryByHexID(
-		c.Context(),
-		ref.MongoAchievementID,
-		"verified",
-	)
-	if err != nil {
-		fmt.Println("MONGO HISTORY ERROR:", err)
+	ref, status, msg := s.workflow.Transition(c, workflow.RequestRevision, req.Note)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	fmt.Println("VERIFY SUCCESS")
-	return helper.Success(c, "verified")
+	return helper.Success(c, ref.Status)
 }
 
-func (s *lecturerAchievementService) Reject(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
-	user := c.Locals("user").(models.Users)
-	now := time.Now()
+const maxBulkReview = 100
 
+// aksi bulk -> event workflow beserta permission RBAC endpoint satuannya
+var bulkReviewActions = map[string]struct {
+	event       workflow.Event
+	permissions []string
+}{
+	"verify":           {workflow.Verify, []string{"achievement:verify"}},
+	"reject":           {workflow.Reject, []string{"achievement:reject"}},
+	"request_revision": {workflow.RequestRevision, []string{"achievement:verify", "achievement:reject"}},
+}
+
+type bulkReviewResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Status  string `json:"status,omitempty"`
+	Code    int    `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkReview menjalankan verify / reject / request revision untuk banyak
+// prestasi sekaligus dengan satu catatan bersama. Tiap item dicek sendiri
+// (dosen wali / delegasi / stage, status saat ini); item yang gagal tidak
+// membatalkan item lain.
+func (s *lecturerAchievementService) BulkReview(c *fiber.Ctx) error {
 	var req struct {
-		Note string `json:"note"`
+		IDs    []string `json:"ids"`
+		Action string   `json:"action"`
+		Note   string   `json:"note"`
 	}
 	if err := c.BodyParser(&req); err != nil {
 		return helper.Error(c, 400, "invalid request body")
 	}
 
-	lecturer, _ := s.lecturerRepo.FindByUserID(c.Context(), user.ID.String())
-	ref, _ := s.repo.FindByID(c.Context(), refID)
+	action, ok := bulkReviewActions[req.Action]
+	if !ok {
+		return helper.Error(c, 400, "action must be verify, reject or request_revision")
+	}
 
-	if ref.Status != "submitted" {
-		return helper.Error(c, 400, "hanya item tersubmit yang dapat ditolak")
+	// catatan dipakai bersama semua item, jadi dicek sekali di sini
+	if t, _ := workflow.Lookup(action.event); t.RequiresNote && strings.TrimSpace(req.Note) == "" {
+		return helper.Error(c, 400, "note is required for "+req.Action)
 	}
 
-	student, _ := s.studentRepo.FindByID(c.Context(), ref.StudentID)
-	if student.AdvisorID != lecturer.ID {
-		return helper.Error(c, 403, "bukan mahasiswa bimbingan")
+	if len(req.IDs) == 0 {
+		return helper.Error(c, 400, "ids is required")
+	}
+	if len(req.IDs) > maxBulkReview {
+		return helper.Error(c, 400, fmt.Sprintf("at most %d achievements per request", maxBulkReview))
 	}
 
-	err := s.repo.UpdateStatusRejected(
-		c.Context(),
-		refID,
-		user.ID,
-		req.Note,
-		now,
-	)
+	actor, err := s.policies.Actor(c)
 	if err != nil {
-		return helper.Error(c, 500, "gagal untuk reject")
+		return helper.Error(c, 500, "failed to check permissions")
+	}
+	if !actor.IsSuperAdmin() && !hasAnyPermission(actor, action.permissions) {
+		return helper.Error(c, 403, "forbidden: missing permission for "+req.Action)
 	}
 
-	s.mongo.PushHistoryByHexID(
-		c.Context(),
-		ref.MongoAchievementID,
-		"rejected",
-	)
+	results := make([]bulkReviewResult, 0, len(req.IDs))
+	seen := make(map[uuid.UUID]bool, len(req.IDs))
+	succeeded := 0
+
+	for _, raw := range req.IDs {
+		result := bulkReviewResult{ID: raw}
+
+		refID, err := uuid.Parse(raw)
+		switch {
+		case err != nil:
+			result.Code, result.Error = 400, "invalid id"
+		case seen[refID]:
+			result.Code, result.Error = 400, "duplicate id"
+		default:
+			seen[refID] = true
+
+			ref, status, msg := s.workflow.TransitionAs(c, actor, refID, action.event, req.Note)
+			if status != 0 {
+				result.Code, result.Error = status, msg
+			} else {
+				result.Success, result.Status = true, ref.Status
+				succeeded++
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return helper.Success(c, fiber.Map{
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
 
-	return helper.Success(c, "rejected")
+func hasAnyPermission(actor policy.Actor, permissions []string) bool {
+	for _, p := range permissions {
+		if actor.Permissions[p] {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *lecturerAchievementService) GetHistory(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
-	user := c.Locals("user").(models.Users)
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
 
-	lecturer, err := s.lecturerRepo.FindByUserID(c.Context(), user.ID.String())
+	history, err := s.mongo.GetHistory(c.Context(), ref.MongoAchievementID)
 	if err != nil {
-		return helper.Error(c, 403, "bukan dosen")
+		return helper.Error(c, 500, "gagal mendapatkan history")
 	}
 
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "prestasi tidak ditemukan")
+	return helper.Success(c, history)
+}
+
+func (s *lecturerAchievementService) GetSubmissions(c *fiber.Ctx) error {
+	rounds, status, msg := s.workflow.Submissions(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	student, err := s.studentRepo.FindByID(c.Context(), ref.StudentID)
-	if err != nil {
-		return helper.Error(c, 404, "mahasiswa tidak ditemukan")
+	return helper.Success(c, rounds)
+}
+
+func (s *lecturerAchievementService) GetVersions(c *fiber.Ctx) error {
+	list, status, msg := s.versions.List(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	if student.AdvisorID != lecturer.ID {
-		return helper.Error(c, 403, "bukan mahasiswa bimbingan")
+	return helper.Success(c, list)
+}
+
+func (s *lecturerAchievementService) GetVersion(c *fiber.Ctx) error {
+	version, status, msg := s.versions.Get(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	history, err := s.mongo.GetHistory(c.Context(), ref.MongoAchievementID)
-	if err != nil {
-		return helper.Error(c, 500, "gagal mendapatkan history")
+	return helper.Success(c, version)
+}
+
+func (s *lecturerAchievementService) DiffVersions(c *fiber.Ctx) error {
+	diff, status, msg := s.versions.Diff(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	return helper.Success(c, history)
+	return helper.Success(c, diff)
 }
@@ -34,13 +34,22 @@ func (s *lecturerService) CreateProfile(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid body")
 	}
 
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid user_id")
+	}
+
 	lecturer := models.Lecturer{
 		ID:         uuid.New(),
-		UserID:     uuid.MustParse(req.UserID),
+		UserID:     userID,
 		LecturerID: req.LecturerID,
 		Department: req.Department,
 	}
 
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		lecturer.OrgUnitID = &scope.RootID
+	}
+
 	if err := s.repo.Create(c.Context(), lecturer); err != nil {
 		return helper.Error(c, 500, "failed create lecturer profile")
 	}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+)
+
+// 2^30 detik sudah jauh di atas maxDelay mana pun yang masuk akal.
+const maxDelayExponent = 30
+
+// loginGuard membatasi tebakan password per username dan per IP.
+type loginGuard struct {
+	users    repository.UserRepository
+	attempts repository.LoginAttemptRepository
+
+	maxAttempts      int
+	maxAttemptsPerIP int
+	window           time.Duration
+	lockout          time.Duration
+	maxDelay         time.Duration
+}
+
+func newLoginGuard(users repository.UserRepository, attempts repository.LoginAttemptRepository) *loginGuard {
+	return &loginGuard{
+		users:            users,
+		attempts:         attempts,
+		maxAttempts:      envInt("LOGIN_MAX_ATTEMPTS", 5),
+		maxAttemptsPerIP: envInt("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		window:           time.Duration(envInt("LOGIN_ATTEMPT_WINDOW_MINUTES", 15)) * time.Minute,
+		lockout:          time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		maxDelay:         time.Minute,
+	}
+}
+
+// loginBlockedError dikembalikan saat percobaan login harus ditolak
+// sebelum password dicek.
+type loginBlockedError struct {
+	Status     int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *loginBlockedError) Error() string {
+	return e.Message
+}
+
+func (g *loginGuard) CheckIP(ctx context.Context, ip string) error {
+	failures, err := g.attempts.CountFailuresByIP(ctx, ip, time.Now().Add(-g.window))
+	if err != nil {
+		return err
+	}
+
+	if failures >= g.maxAttemptsPerIP {
+		return &loginBlockedError{
+			Status:     429,
+			Message:    "too many failed login attempts from this address",
+			RetryAfter: g.window,
+		}
+	}
+
+	return nil
+}
+
+// CheckUser menolak login selama akun terkunci atau masih dalam masa
+// jeda progresif setelah gagal login.
+func (g *loginGuard) CheckUser(user models.Users) error {
+	now := time.Now()
+
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return &loginBlockedError{
+			Status:     423,
+			Message:    "account is temporarily locked",
+			RetryAfter: user.LockedUntil.Sub(now),
+		}
+	}
+
+	if user.FailedLoginCount > 0 && user.LastFailedLoginAt != nil {
+		next := user.LastFailedLoginAt.Add(g.delay(user.FailedLoginCount))
+		if now.Before(next) {
+			return &loginBlockedError{
+				Status:     429,
+				Message:    "too many failed login attempts, please wait",
+				RetryAfter: next.Sub(now),
+			}
+		}
+	}
+
+	return nil
+}
+
+// delay: 1s, 2s, 4s, ... dibatasi maxDelay. Eksponen dibatasi dulu supaya
+// konversi ke time.Duration tidak overflow saat kegagalan sangat banyak.
+func (g *loginGuard) delay(failures int) time.Duration {
+	exp := failures - 1
+	if exp < 0 {
+		exp = 0
+	}
+	if exp > maxDelayExponent {
+		return g.maxDelay
+	}
+
+	d := time.Duration(math.Pow(2, float64(exp))) * time.Second
+	if d > g.maxDelay {
+		return g.maxDelay
+	}
+	return d
+}
+
+// Failed mencatat kegagalan; locked bernilai true jika akun baru saja dikunci.
+func (g *loginGuard) Failed(ctx context.Context, username string, ip string, user *models.Users) (locked bool, err error) {
+	if err := g.attempts.Record(ctx, username, ip, false); err != nil {
+		return false, err
+	}
+
+	if user == nil {
+		return false, nil
+	}
+
+	count, err := g.users.RegisterLoginFailure(ctx, user.ID.String())
+	if err != nil {
+		return false, err
+	}
+
+	if count < g.maxAttempts {
+		return false, nil
+	}
+
+	if err := g.users.Lock(ctx, user.ID.String(), time.Now().Add(g.lockout)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (g *loginGuard) Succeeded(ctx context.Context, username string, ip string, user models.Users) error {
+	if err := g.attempts.Record(ctx, username, ip, true); err != nil {
+		return err
+	}
+
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+
+	return g.users.ResetLoginFailures(ctx, user.ID.String())
+}
+
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
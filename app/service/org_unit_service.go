@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// parent yang valid untuk tiap tipe unit
+var orgUnitParentType = map[string]string{
+	models.OrgUnitUniversity: "",
+	models.OrgUnitFaculty:    models.OrgUnitUniversity,
+	models.OrgUnitDepartment: models.OrgUnitFaculty,
+}
+
+type OrgUnitService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	AssignStudent(c *fiber.Ctx) error
+	AssignLecturer(c *fiber.Ctx) error
+}
+
+type orgUnitService struct {
+	repo      repository.OrgUnitRepository
+	students  repository.StudentRepository
+	lecturers repository.LecturerRepository
+}
+
+func NewOrgUnitService(
+	repo repository.OrgUnitRepository,
+	students repository.StudentRepository,
+	lecturers repository.LecturerRepository,
+) OrgUnitService {
+	return &orgUnitService{repo, students, lecturers}
+}
+
+// inOrgScope bernilai true kalau request tidak dibatasi unit, atau unitID
+// ada di dalam scope admin unit yang sedang login.
+func inOrgScope(ctx context.Context, unitID *uuid.UUID) bool {
+	scope, ok := repository.OrgScopeFromContext(ctx)
+	return !ok || scope.Contains(unitID)
+}
+
+func (s *orgUnitService) GetAll(c *fiber.Ctx) error {
+	units, err := s.repo.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch org units")
+	}
+	return helper.Success(c, units)
+}
+
+func (s *orgUnitService) Create(c *fiber.Ctx) error {
+	var req struct {
+		Name     string `json:"name"`
+		Type     string `json:"type"`
+		ParentID string `json:"parentId"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return helper.Error(c, 400, "name is required")
+	}
+
+	parentType, ok := orgUnitParentType[req.Type]
+	if !ok {
+		return helper.Error(c, 400, "type must be university, faculty or department")
+	}
+
+	unit := models.OrgUnit{
+		ID:   uuid.New(),
+		Name: req.Name,
+		Type: req.Type,
+	}
+
+	if parentType == "" {
+		if req.ParentID != "" {
+			return helper.Error(c, 400, "a university cannot have a parent")
+		}
+	} else {
+		parentID, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			return helper.Error(c, 400, "parentId is required")
+		}
+
+		parent, err := s.repo.FindByID(c.Context(), parentID)
+		if err != nil {
+			return helper.Error(c, 404, "parent unit not found")
+		}
+		if parent.Type != parentType {
+			return helper.Error(c, 400, "a "+req.Type+" must be placed under a "+parentType)
+		}
+		unit.ParentID = &parent.ID
+	}
+
+	if !inOrgScope(c.Context(), unit.ParentID) {
+		return helper.Error(c, 403, "parent unit is outside your scope")
+	}
+
+	err := s.repo.Create(c.Context(), unit)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "org unit name already exists under this parent")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed create org unit")
+	}
+
+	return helper.Success(c, unit)
+}
+
+// scopedUnit membaca :id dan memastikan unit ada di scope caller.
+func (s *orgUnitService) scopedUnit(c *fiber.Ctx) (models.OrgUnit, int, string) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.OrgUnit{}, 400, "invalid id"
+	}
+
+	unit, err := s.repo.FindByID(c.Context(), id)
+	if err != nil || !inOrgScope(c.Context(), &unit.ID) {
+		return models.OrgUnit{}, 404, "org unit not found"
+	}
+
+	return unit, 0, ""
+}
+
+func (s *orgUnitService) Update(c *fiber.Ctx) error {
+	unit, status, msg := s.scopedUnit(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	unit.Name = strings.TrimSpace(req.Name)
+	if unit.Name == "" {
+		return helper.Error(c, 400, "name is required")
+	}
+
+	err := s.repo.Update(c.Context(), unit)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "org unit name already exists under this parent")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed update org unit")
+	}
+
+	return helper.Success(c, unit)
+}
+
+func (s *orgUnitService) Delete(c *fiber.Ctx) error {
+	unit, status, msg := s.scopedUnit(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok && scope.RootID == unit.ID {
+		return helper.Error(c, 403, "cannot delete the unit your role is scoped to")
+	}
+
+	err := s.repo.Delete(c.Context(), unit.ID)
+	if errors.Is(err, repository.ErrOrgUnitInUse) {
+		return helper.Error(c, 409, err.Error())
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed delete org unit")
+	}
+
+	return helper.Success(c, "org unit deleted")
+}
+
+func (s *orgUnitService) AssignStudent(c *fiber.Ctx) error {
+	unit, status, msg := s.scopedUnit(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	studentID, err := uuid.Parse(c.Params("studentId"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid student id")
+	}
+
+	// admin unit hanya boleh memindahkan mahasiswa yang belum punya unit
+	// atau yang sudah berada di unit-nya
+	student, err := s.students.FindByID(c.Context(), studentID)
+	if err != nil || (student.OrgUnitID != nil && !inOrgScope(c.Context(), student.OrgUnitID)) {
+		return helper.Error(c, 404, "mahasiswa tidak ditemukan")
+	}
+
+	if err := s.repo.AssignStudent(c.Context(), unit.ID, student.ID); err != nil {
+		return helper.Error(c, 500, "failed assign student")
+	}
+
+	return helper.Success(c, "student assigned")
+}
+
+func (s *orgUnitService) AssignLecturer(c *fiber.Ctx) error {
+	unit, status, msg := s.scopedUnit(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	lecturerID, err := uuid.Parse(c.Params("lecturerId"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid lecturer id")
+	}
+
+	lecturer, err := s.lecturers.FindByID(c.Context(), lecturerID)
+	if err != nil || (lecturer.OrgUnitID != nil && !inOrgScope(c.Context(), lecturer.OrgUnitID)) {
+		return helper.Error(c, 404, "dosen tidak ditemukan")
+	}
+
+	if err := s.repo.AssignLecturer(c.Context(), unit.ID, lecturer.ID); err != nil {
+		return helper.Error(c, 500, "failed assign lecturer")
+	}
+
+	return helper.Success(c, "lecturer assigned")
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PermissionService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetUserPermissions(c *fiber.Ctx) error
+}
+
+type permissionService struct {
+	repo  repository.PermissionRepository
+	cache *middleware.PermissionCache
+}
+
+func NewPermissionService(
+	repo repository.PermissionRepository,
+	cache *middleware.PermissionCache,
+) PermissionService {
+	return &permissionService{repo, cache}
+}
+
+func (s *permissionService) GetAll(c *fiber.Ctx) error {
+	perms, err := s.repo.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch permissions")
+	}
+	return helper.Success(c, perms)
+}
+
+func (s *permissionService) Create(c *fiber.Ctx) error {
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	// format permission: "resource:action", contoh "report:read"
+	req.Name = strings.TrimSpace(req.Name)
+	if resource, action, ok := strings.Cut(req.Name, ":"); !ok || resource == "" || action == "" {
+		return helper.Error(c, 400, "name must use the format resource:action")
+	}
+
+	perm := models.Permission{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	err := s.repo.Create(c.Context(), perm)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "permission already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed create permission")
+	}
+
+	return helper.Success(c, perm)
+}
+
+func (s *permissionService) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	perm, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "permission not found")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = perm.Name
+	}
+
+	// nama permission sistem dipakai langsung di route (RequirePermission)
+	if perm.IsSystem && perm.Name != req.Name {
+		return helper.Error(c, 403, "system permissions cannot be renamed")
+	}
+
+	if resource, action, ok := strings.Cut(req.Name, ":"); !ok || resource == "" || action == "" {
+		return helper.Error(c, 400, "name must use the format resource:action")
+	}
+
+	perm.Name = req.Name
+	perm.Description = req.Description
+
+	err = s.repo.Update(c.Context(), perm)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "permission already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed update permission")
+	}
+
+	// nama permission tersimpan di cache per role
+	s.cache.InvalidateAll()
+
+	return helper.Success(c, perm)
+}
+
+func (s *permissionService) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	perm, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "permission not found")
+	}
+
+	if perm.IsSystem {
+		return helper.Error(c, 403, "system permissions cannot be deleted")
+	}
+
+	if err := s.repo.Delete(c.Context(), id); err != nil {
+		return helper.Error(c, 500, "failed delete permission")
+	}
+
+	s.cache.InvalidateAll()
+
+	return helper.Success(c, "permission deleted")
+}
+
+// GetUserPermissions menampilkan permission efektif user lewat role-nya.
+func (s *permissionService) GetUserPermissions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	perms, err := s.repo.FindByUser(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch user permissions")
+	}
+
+	return helper.Success(c, perms)
+}
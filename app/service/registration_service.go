@@ -0,0 +1,313 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const registrationVerifyTTL = 24 * time.Hour
+
+type RegistrationService interface {
+	Register(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	GetPending(c *fiber.Ctx) error
+	Approve(c *fiber.Ctx) error
+	Reject(c *fiber.Ctx) error
+}
+
+type registrationService struct {
+	repo   repository.RegistrationRepository
+	users  repository.UserRepository
+	roles  repository.RoleRepository
+	keys   *helper.KeySet
+	mailer helper.Mailer
+	hasher helper.PasswordHasher
+}
+
+func NewRegistrationService(
+	repo repository.RegistrationRepository,
+	users repository.UserRepository,
+	roles repository.RoleRepository,
+	keys *helper.KeySet,
+	mailer helper.Mailer,
+	hasher helper.PasswordHasher,
+) RegistrationService {
+	return &registrationService{repo, users, roles, keys, mailer, hasher}
+}
+
+// Register menerima pendaftaran mandiri mahasiswa (publik). NIM dipakai
+// sebagai username setelah disetujui.
+func (s *registrationService) Register(c *fiber.Ctx) error {
+	var req struct {
+		FullName     string `json:"fullName"`
+		Email        string `json:"email"`
+		StudentID    string `json:"studentId"`
+		ProgramStudy string `json:"programStudy"`
+		AcademicYear string `json:"academicYear"`
+		Password     string `json:"password"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	req.StudentID = strings.TrimSpace(req.StudentID)
+
+	if req.FullName == "" || req.StudentID == "" || req.ProgramStudy == "" || req.AcademicYear == "" {
+		return helper.Error(c, 400, "fullName, studentId, programStudy and academicYear are required")
+	}
+
+	if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		return helper.Error(c, 400, "invalid email address")
+	}
+
+	if err := helper.ValidatePassword(req.Password); err != nil {
+		return helper.Error(c, 400, err.Error())
+	}
+
+	ctx := c.Context()
+
+	if err := s.repo.DeleteUnverifiedBefore(ctx, time.Now().Add(-registrationVerifyTTL)); err != nil {
+		fmt.Println("ERROR cleanup registrations:", err)
+	}
+
+	if _, err := s.users.FindByEmail(ctx, req.Email); err == nil {
+		return helper.Error(c, 409, "email or student ID already registered")
+	}
+	if _, err := s.users.FindByUsername(ctx, req.StudentID); err == nil {
+		return helper.Error(c, 409, "email or student ID already registered")
+	}
+
+	exists, err := s.repo.ExistsActive(ctx, req.Email, req.StudentID)
+	if err != nil {
+		return helper.Error(c, 500, "failed to register")
+	}
+	if exists {
+		return helper.Error(c, 409, "a registration for this email or student ID is already in progress")
+	}
+
+	hash, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return helper.Error(c, 500, "failed to register")
+	}
+
+	reg := models.StudentRegistration{
+		ID:           uuid.New(),
+		FullName:     req.FullName,
+		Email:        req.Email,
+		StudentID:    req.StudentID,
+		ProgramStudy: req.ProgramStudy,
+		AcademicYear: req.AcademicYear,
+		PasswordHash: hash,
+	}
+
+	if err := s.repo.Create(ctx, reg); err != nil {
+		fmt.Println("ERROR create registration:", err)
+		return helper.Error(c, 500, "failed to register")
+	}
+
+	token, err := s.keys.Sign(helper.TokenRegistration, jwt.MapClaims{
+		"rid": reg.ID.String(),
+		"exp": time.Now().Add(registrationVerifyTTL).Unix(),
+	})
+	if err != nil {
+		return helper.Error(c, 500, "failed to create verification link")
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKonfirmasi email pendaftaran kamu lewat link berikut (berlaku %d jam):\n%s?token=%s\n\nSetelah itu pendaftaran akan diperiksa oleh admin.",
+		reg.FullName,
+		int(registrationVerifyTTL.Hours()),
+		os.Getenv("REGISTRATION_VERIFY_URL"),
+		token,
+	)
+
+	if err := s.mailer.Send(ctx, reg.Email, "Konfirmasi pendaftaran", body); err != nil {
+		fmt.Println("ERROR SEND MAIL:", err)
+	}
+
+	return helper.Success(c, "registration received, please check your email to confirm it")
+}
+
+func (s *registrationService) VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		var req struct {
+			Token string `json:"token"`
+		}
+		_ = c.BodyParser(&req)
+		token = req.Token
+	}
+
+	claims, err := s.keys.Parse(helper.TokenRegistration, token)
+	if err != nil {
+		return helper.Error(c, 400, "invalid or expired verification link")
+	}
+
+	ridStr, _ := claims["rid"].(string)
+	rid, err := uuid.Parse(ridStr)
+	if err != nil {
+		return helper.Error(c, 400, "invalid verification link")
+	}
+
+	ok, err := s.repo.MarkEmailVerified(c.Context(), rid)
+	if err != nil {
+		return helper.Error(c, 500, "failed to verify email")
+	}
+
+	if !ok {
+		return helper.Error(c, 400, "registration already verified or no longer pending")
+	}
+
+	return helper.Success(c, "email verified, your registration is waiting for admin approval")
+}
+
+func (s *registrationService) GetPending(c *fiber.Ctx) error {
+	status := c.Query("status", models.RegistrationPendingApproval)
+
+	list, err := s.repo.FindByStatus(c.Context(), status)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch registrations")
+	}
+
+	return helper.Success(c, list)
+}
+
+// Approve membuat akun dengan role mahasiswa (REGISTRATION_STUDENT_ROLE,
+// default "Mahasiswa") beserta profil students-nya.
+func (s *registrationService) Approve(c *fiber.Ctx) error {
+	admin := c.Locals("user").(models.Users)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		AdvisorID string `json:"advisorId"`
+	}
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	var advisorID uuid.UUID
+	if req.AdvisorID != "" {
+		if advisorID, err = uuid.Parse(req.AdvisorID); err != nil {
+			return helper.Error(c, 400, "invalid advisorId")
+		}
+	}
+
+	reg, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "registration not found")
+	}
+
+	if reg.Status != models.RegistrationPendingApproval {
+		return helper.Error(c, 409, repository.ErrRegistrationNotPending.Error())
+	}
+
+	roleName := os.Getenv("REGISTRATION_STUDENT_ROLE")
+	if roleName == "" {
+		roleName = "Mahasiswa"
+	}
+
+	role, err := s.roles.FindByName(c.Context(), roleName)
+	if err != nil {
+		fmt.Println("ERROR student role:", err)
+		return helper.Error(c, 500, "student role "+roleName+" not found")
+	}
+
+	user := models.Users{
+		ID:           uuid.New(),
+		Username:     reg.StudentID,
+		Email:        reg.Email,
+		PasswordHash: reg.PasswordHash,
+		FullName:     reg.FullName,
+		RoleID:       role.ID,
+		IsActive:     true,
+	}
+
+	student := models.Student{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		StudentID:    reg.StudentID,
+		ProgramStudy: reg.ProgramStudy,
+		AcademicYear: reg.AcademicYear,
+		AdvisorID:    advisorID,
+	}
+
+	err = s.repo.Approve(c.Context(), reg.ID, admin.ID, user, student)
+	if errors.Is(err, repository.ErrRegistrationNotPending) {
+		return helper.Error(c, 409, err.Error())
+	}
+	if err != nil {
+		fmt.Println("ERROR approve registration:", err)
+		return helper.Error(c, 500, "failed to approve registration")
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nPendaftaran kamu sudah disetujui. Silakan login dengan username %s.",
+		reg.FullName,
+		reg.StudentID,
+	)
+	if err := s.mailer.Send(c.Context(), reg.Email, "Pendaftaran disetujui", body); err != nil {
+		fmt.Println("ERROR SEND MAIL:", err)
+	}
+
+	return helper.Success(c, fiber.Map{
+		"user":    user,
+		"student": student,
+	})
+}
+
+func (s *registrationService) Reject(c *fiber.Ctx) error {
+	admin := c.Locals("user").(models.Users)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		return helper.Error(c, 400, "reason is required")
+	}
+
+	reg, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "registration not found")
+	}
+
+	ok, err := s.repo.Reject(c.Context(), id, admin.ID, req.Reason)
+	if err != nil {
+		return helper.Error(c, 500, "failed to reject registration")
+	}
+	if !ok {
+		return helper.Error(c, 409, repository.ErrRegistrationNotPending.Error())
+	}
+
+	body := fmt.Sprintf(
+		"Halo %s,\n\nMaaf, pendaftaran kamu ditolak dengan alasan:\n%s",
+		reg.FullName,
+		req.Reason,
+	)
+	if err := s.mailer.Send(c.Context(), reg.Email, "Pendaftaran ditolak", body); err != nil {
+		fmt.Println("ERROR SEND MAIL:", err)
+	}
+
+	return helper.Success(c, "registration rejected")
+}
@@ -2,10 +2,11 @@ package service
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 
 	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
+	"uas/app/workflow"
 	"uas/helper"
 )
 
@@ -19,6 +20,7 @@ type reportService struct {
 	mongoRepo       repository.MongoAchievementRepository
 	studentRepo     repository.StudentRepository
 	lecturerRepo    repository.LecturerRepository
+	policies        *policy.Resolver
 }
 
 func NewReportService(
@@ -26,12 +28,14 @@ func NewReportService(
 	mongoRepo repository.MongoAchievementRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	policies *policy.Resolver,
 ) ReportService {
 	return &reportService{
 		achievementRepo,
 		mongoRepo,
 		studentRepo,
 		lecturerRepo,
+		policies,
 	}
 }
 
@@ -66,7 +70,7 @@ func (s *reportService) GetStatistics(c *fiber.Ctx) error {
 	studentCount := map[string]int{}
 
 	for _, ref := range refs {
-		if ref.Status != "verified" {
+		if ref.Status != workflow.Verified {
 			continue
 		}
 
@@ -98,24 +102,13 @@ func (s *reportService) GetStatistics(c *fiber.Ctx) error {
 }
 
 func (s *reportService) GetStudentStatistics(c *fiber.Ctx) error {
-	user := c.Locals("user").(models.Users)
-	studentID := uuid.MustParse(c.Params("id"))
-
-	if lecturer, errLect := s.lecturerRepo.FindByUserID(c.Context(), user.ID.String()); errLect == nil {
-
-		student, err := s.studentRepo.FindByID(c.Context(), studentID)
-		if err != nil || student.AdvisorID != lecturer.ID {
-			return helper.Error(c, 403, "forbidden")
-		}
-
-	} else if student, errStd := s.studentRepo.FindByUserID(c.Context(), user.ID.String()); errStd == nil {
-
-		if student.ID != studentID {
-			return helper.Error(c, 403, "forbidden")
-		}
-
+	student, status, msg := authorizeStudent(c, s.studentRepo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
+	studentID := student.ID
+
 	refs, err := s.achievementRepo.FindByStudent(c.Context(), studentID)
 	if err != nil {
 		return helper.Error(c, 500, "failed load achievements")
@@ -126,7 +119,7 @@ func (s *reportService) GetStudentStatistics(c *fiber.Ctx) error {
 	periodCount := map[string]int{}
 
 	for _, ref := range refs {
-		if ref.Status != "verified" {
+		if ref.Status != workflow.Verified {
 			continue
 		}
 
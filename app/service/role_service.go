@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+	"uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	AttachPermission(c *fiber.Ctx) error
+	DetachPermission(c *fiber.Ctx) error
+	UpdateMFAPolicy(c *fiber.Ctx) error
+}
+
+type roleService struct {
+	repo        repository.RoleRepository
+	permissions repository.PermissionRepository
+	cache       *middleware.PermissionCache
+}
+
+func NewRoleService(
+	repo repository.RoleRepository,
+	permissions repository.PermissionRepository,
+	cache *middleware.PermissionCache,
+) RoleService {
+	return &roleService{repo, permissions, cache}
+}
+
+func (s *roleService) GetAll(c *fiber.Ctx) error {
+	roles, err := s.repo.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch roles")
+	}
+	return helper.Success(c, roles)
+}
+
+func (s *roleService) GetByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	role, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "role not found")
+	}
+
+	perms, err := s.permissions.FindByRole(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch role permissions")
+	}
+
+	return helper.Success(c, fiber.Map{
+		"role":        role,
+		"permissions": perms,
+	})
+}
+
+func (s *roleService) Create(c *fiber.Ctx) error {
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		MFARequired bool   `json:"mfaRequired"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return helper.Error(c, 400, "name is required")
+	}
+
+	role := models.Role{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		MFARequired: req.MFARequired,
+	}
+
+	err := s.repo.Create(c.Context(), role)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "role name already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed create role")
+	}
+
+	return helper.Success(c, role)
+}
+
+func (s *roleService) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return helper.Error(c, 400, "name is required")
+	}
+
+	role, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "role not found")
+	}
+
+	// nama role sistem dipakai kode / konfigurasi (mis. REGISTRATION_STUDENT_ROLE)
+	if role.IsSystem && role.Name != req.Name {
+		return helper.Error(c, 403, "system roles cannot be renamed")
+	}
+
+	role.Name = req.Name
+	role.Description = req.Description
+
+	err = s.repo.Update(c.Context(), role)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return helper.Error(c, 409, "role name already exists")
+	}
+	if err != nil {
+		return helper.Error(c, 500, "failed update role")
+	}
+
+	return helper.Success(c, role)
+}
+
+func (s *roleService) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	role, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "role not found")
+	}
+
+	if role.IsSystem {
+		return helper.Error(c, 403, "system roles cannot be deleted")
+	}
+
+	count, err := s.repo.CountUsers(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 500, "failed delete role")
+	}
+	if count > 0 {
+		return helper.Error(c, 409, "role is still assigned to users")
+	}
+
+	if err := s.repo.Delete(c.Context(), id); err != nil {
+		return helper.Error(c, 500, "failed delete role")
+	}
+
+	s.cache.Invalidate(id)
+
+	return helper.Success(c, "role deleted")
+}
+
+// rolePermissionParams membaca :id dan :permissionId. Kalau gagal,
+// status != 0 berisi kode HTTP dan pesan error-nya.
+func (s *roleService) rolePermissionParams(c *fiber.Ctx) (models.Role, models.Permission, int, string) {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return models.Role{}, models.Permission{}, 400, "invalid id"
+	}
+
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return models.Role{}, models.Permission{}, 400, "invalid permission id"
+	}
+
+	role, err := s.repo.FindByID(c.Context(), roleID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, 404, "role not found"
+	}
+
+	perm, err := s.permissions.FindByID(c.Context(), permissionID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, 404, "permission not found"
+	}
+
+	return role, perm, 0, ""
+}
+
+func (s *roleService) AttachPermission(c *fiber.Ctx) error {
+	role, perm, status, msg := s.rolePermissionParams(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	if err := s.repo.AttachPermission(c.Context(), role.ID, perm.ID); err != nil {
+		return helper.Error(c, 500, "failed attach permission")
+	}
+
+	s.cache.Invalidate(role.ID)
+
+	return helper.Success(c, "permission attached")
+}
+
+func (s *roleService) DetachPermission(c *fiber.Ctx) error {
+	role, perm, status, msg := s.rolePermissionParams(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	// jangan sampai admin mengunci dirinya sendiri dari manajemen user
+	user := c.Locals("user").(models.Users)
+	if perm.Name == "user:manage" && user.RoleID == role.ID {
+		return helper.Error(c, 403, "cannot remove user:manage from your own role")
+	}
+
+	if err := s.repo.DetachPermission(c.Context(), role.ID, perm.ID); err != nil {
+		return helper.Error(c, 500, "failed detach permission")
+	}
+
+	s.cache.Invalidate(role.ID)
+
+	return helper.Success(c, "permission detached")
+}
+
+func (s *roleService) UpdateMFAPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		MFARequired bool `json:"mfaRequired"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id); err != nil {
+		return helper.Error(c, 404, "role not found")
+	}
+
+	if err := s.repo.UpdateMFARequired(c.Context(), id, req.MFARequired); err != nil {
+		return helper.Error(c, 500, "failed to update MFA policy")
+	}
+
+	return helper.Success(c, "MFA policy updated")
+}
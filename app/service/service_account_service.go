@@ -0,0 +1,210 @@
+package service
+
+import (
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ServiceAccountService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Deactivate(c *fiber.Ctx) error
+	ListKeys(c *fiber.Ctx) error
+	CreateKey(c *fiber.Ctx) error
+	RevokeKey(c *fiber.Ctx) error
+}
+
+type serviceAccountService struct {
+	repo        repository.ServiceAccountRepository
+	permissions repository.PermissionRepository
+	users       repository.AdminUserRepository
+}
+
+func NewServiceAccountService(
+	repo repository.ServiceAccountRepository,
+	permissions repository.PermissionRepository,
+	users repository.AdminUserRepository,
+) ServiceAccountService {
+	return &serviceAccountService{repo, permissions, users}
+}
+
+func (s *serviceAccountService) GetAll(c *fiber.Ctx) error {
+	accounts, err := s.repo.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch service accounts")
+	}
+	return helper.Success(c, accounts)
+}
+
+func (s *serviceAccountService) Create(c *fiber.Ctx) error {
+	admin := c.Locals("user").(models.Users)
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	if req.Name == "" {
+		return helper.Error(c, 400, "name is required")
+	}
+
+	sa := models.ServiceAccount{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    true,
+		CreatedBy:   admin.ID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := s.repo.Create(c.Context(), sa); err != nil {
+		return helper.Error(c, 500, "failed create service account")
+	}
+
+	return helper.Success(c, sa)
+}
+
+func (s *serviceAccountService) Deactivate(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id); err != nil {
+		return helper.Error(c, 404, "service account not found")
+	}
+
+	if err := s.repo.Deactivate(c.Context(), id); err != nil {
+		return helper.Error(c, 500, "failed deactivate service account")
+	}
+
+	return helper.Success(c, "service account deactivated")
+}
+
+func (s *serviceAccountService) ListKeys(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	keys, err := s.repo.FindKeysByAccount(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch API keys")
+	}
+
+	return helper.Success(c, keys)
+}
+
+// CreateKey membuat API key baru. Key mentah hanya dikembalikan sekali di
+// response ini, yang disimpan hanya prefix dan hash-nya.
+func (s *serviceAccountService) CreateKey(c *fiber.Ctx) error {
+	admin := c.Locals("userID").(string)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	var req struct {
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid body")
+	}
+
+	if len(req.Scopes) == 0 {
+		return helper.Error(c, 400, "at least one scope is required")
+	}
+
+	sa, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "service account not found")
+	}
+	if !sa.IsActive {
+		return helper.Error(c, 400, "service account is not active")
+	}
+
+	known, err := s.permissions.FindAll(c.Context())
+	if err != nil {
+		return helper.Error(c, 500, "failed load permissions")
+	}
+
+	// admin tidak boleh memberi scope yang dia sendiri tidak punya
+	own, err := s.users.GetUserPermissions(c.Context(), admin)
+	if err != nil {
+		return helper.Error(c, 500, "failed load permissions")
+	}
+
+	validScopes := make(map[string]bool, len(known))
+	for _, p := range known {
+		validScopes[p.Name] = true
+	}
+	ownScopes := make(map[string]bool, len(own))
+	for _, p := range own {
+		ownScopes[p] = true
+	}
+
+	for _, scope := range req.Scopes {
+		if !validScopes[scope] {
+			return helper.Error(c, 400, "unknown scope: "+scope)
+		}
+		if !ownScopes[scope] {
+			return helper.Error(c, 403, "cannot grant scope you do not have: "+scope)
+		}
+	}
+
+	rawKey, prefix, err := helper.GenerateAPIKey()
+	if err != nil {
+		return helper.Error(c, 500, "failed generate API key")
+	}
+
+	key := models.APIKey{
+		ID:               uuid.New(),
+		ServiceAccountID: sa.ID,
+		Prefix:           prefix,
+		KeyHash:          helper.HashToken(rawKey),
+		Scopes:           req.Scopes,
+		CreatedAt:        time.Now(),
+	}
+
+	if req.ExpiresInDays > 0 {
+		exp := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &exp
+	}
+
+	if err := s.repo.CreateKey(c.Context(), key); err != nil {
+		return helper.Error(c, 500, "failed create API key")
+	}
+
+	return helper.Success(c, fiber.Map{
+		"key":    rawKey,
+		"apiKey": key,
+	})
+}
+
+func (s *serviceAccountService) RevokeKey(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	keyID, err := uuid.Parse(c.Params("keyId"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid key id")
+	}
+
+	if err := s.repo.RevokeKey(c.Context(), id, keyID); err != nil {
+		return helper.Error(c, 500, "failed revoke API key")
+	}
+
+	return helper.Success(c, "API key revoked")
+}
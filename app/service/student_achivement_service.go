@@ -1,11 +1,15 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
+	"uas/app/workflow"
 	"uas/helper"
 
 	"github.com/gofiber/fiber/v2"
@@ -23,20 +27,40 @@ type StudentAchievementService interface {
 	GetDetail(c *fiber.Ctx) error
 	GetHistory(c *fiber.Ctx) error
 	UploadAttachment(c *fiber.Ctx) error
+	GetSubmissions(c *fiber.Ctx) error
+	GetVersions(c *fiber.Ctx) error
+	GetVersion(c *fiber.Ctx) error
+	DiffVersions(c *fiber.Ctx) error
 }
 
 type studentAchievementService struct {
 	repo        repository.AchievementRepository
 	studentRepo repository.StudentRepository
 	mongo       repository.MongoAchievementRepository
+	policies    *policy.Resolver
+	workflow    *achievementWorkflow
+	versions    *achievementVersions
 }
 
 func NewStudentAchievementService(
 	repo repository.AchievementRepository,
 	studentRepo repository.StudentRepository,
 	mongo repository.MongoAchievementRepository,
+	submissions repository.SubmissionRepository,
+	versions repository.AchievementVersionRepository,
+	approvals repository.ApprovalRepository,
+	chains repository.ApprovalChainRepository,
+	transitions repository.TransitionRepository,
+	policies *policy.Resolver,
 ) StudentAchievementService {
-	return &studentAchievementService{repo, studentRepo, mongo}
+	return &studentAchievementService{
+		repo:        repo,
+		studentRepo: studentRepo,
+		mongo:       mongo,
+		policies:    policies,
+		workflow:    &achievementWorkflow{repo, mongo, submissions, approvals, chains, transitions, policies},
+		versions:    &achievementVersions{repo, mongo, versions, policies},
+	}
 }
 
 func (s *studentAchievementService) Create(c *fiber.Ctx) error {
@@ -72,47 +96,64 @@ func (s *studentAchievementService) Create(c *fiber.Ctx) error {
 			Status    string    `bson:"status"`
 			Timestamp time.Time `bson:"timestamp"`
 			ChangedBy string    `bson:"changedBy"`
+			Note      string    `bson:"note,omitempty"`
 		}{
-			{Status: "draft", Timestamp: now, ChangedBy: user.ID.String()},
+			{Status: workflow.Draft, Timestamp: now, ChangedBy: user.ID.String()},
 		},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if _, err := s.mongo.Insert(c.Context(), detail); err != nil {
-		return helper.Error(c, 500, "failed insert mongo")
-	}
-
 	ref := models.AchievementRef{
 		ID:                 uuid.New(),
 		StudentID:          student.ID,
 		MongoAchievementID: detail.ID.Hex(),
-		Status:             "draft",
+		Status:             workflow.Draft,
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
 
-	if err := s.repo.CreateReference(c.Context(), ref); err != nil {
-		return helper.Error(c, 500, "failed insert reference")
+	failMsg := ""
+	_, err = s.versions.Write(c.Context(), detail.ID.Hex(), models.VersionCreated, user.ID.String(), func() (models.AchievementSnapshot, error) {
+		if _, err := s.mongo.Insert(c.Context(), detail); err != nil {
+			failMsg = "failed insert mongo"
+			return models.AchievementSnapshot{}, err
+		}
+
+		if err := s.repo.CreateReference(c.Context(), ref); err != nil {
+			if err := s.mongo.DeleteByHexID(c.Context(), detail.ID.Hex()); err != nil {
+				fmt.Println("ERROR rollback mongo insert:", err)
+			}
+			failMsg = "failed insert reference"
+			return models.AchievementSnapshot{}, err
+		}
+
+		return detail.Snapshot(), nil
+	})
+	if err != nil {
+		if failMsg == "" {
+			failMsg = "failed record version"
+		}
+		return helper.Error(c, 500, failMsg)
 	}
 
 	return helper.Success(c, fiber.Map{
 		"id":      ref.ID,
 		"mongoId": detail.ID.Hex(),
-		"status":  "draft",
+		"status":  workflow.Draft,
 	})
 }
 
 func (s *studentAchievementService) Update(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
+	user := c.Locals("user").(models.Users)
 
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionUpdate)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	if ref.Status != "draft" {
-		return helper.Error(c, 400, "only draft can be updated")
+	if !workflow.Editable(ref.Status) {
+		return helper.Error(c, 400, "only draft, revision-requested or rejected achievements can be updated")
 	}
 
 	var req struct {
@@ -123,7 +164,14 @@ func (s *studentAchievementService) Update(c *fiber.Ctx) error {
 		EventDate   string `json:"eventDate"`
 	}
 
-	c.BodyParser(&req)
+	if err := c.BodyParser(&req); err != nil {
+		return helper.Error(c, 400, "invalid request body")
+	}
+
+	// prestasi lama belum punya versi; simpan isi sebelum diubah sebagai baseline
+	if err := s.versions.EnsureBaseline(c.Context(), ref.MongoAchievementID); err != nil {
+		return helper.Error(c, 500, "failed record version")
+	}
 
 	update := bson.M{
 		"$set": bson.M{
@@ -138,27 +186,31 @@ func (s *studentAchievementService) Update(c *fiber.Ctx) error {
 			"history": bson.M{
 				"status":    "draft-updated",
 				"timestamp": time.Now(),
+				"changedBy": user.ID.String(),
 			},
 		},
 	}
 
-	err = s.mongo.UpdateByHexID(c.Context(), ref.MongoAchievementID, update)
+	version, failMsg, err := s.writeEditable(c, ref, models.VersionUpdated, update)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return helper.Error(c, 409, "only draft, revision-requested or rejected achievements can be updated")
+	}
 	if err != nil {
-		return helper.Error(c, 500, "failed update mongo")
+		return helper.Error(c, 500, failMsg)
 	}
 
-	return helper.Success(c, "updated")
+	return helper.Success(c, fiber.Map{
+		"version": version.Version,
+	})
 }
 
 func (s *studentAchievementService) Delete(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
-
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionDelete)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	if ref.Status != "draft" {
+	if !workflow.Deletable(ref.Status) {
 		return helper.Error(c, 400, "only draft can be deleted")
 	}
 
@@ -166,7 +218,11 @@ func (s *studentAchievementService) Delete(c *fiber.Ctx) error {
 		return helper.Error(c, 500, "failed delete mongo")
 	}
 
-	if err := s.repo.Delete(c.Context(), refID); err != nil {
+	if err := s.versions.Delete(c.Context(), ref.MongoAchievementID); err != nil {
+		return helper.Error(c, 500, "failed delete versions")
+	}
+
+	if err := s.repo.Delete(c.Context(), ref.ID); err != nil {
 		return helper.Error(c, 500, "failed delete reference")
 	}
 
@@ -174,33 +230,12 @@ func (s *studentAchievementService) Delete(c *fiber.Ctx) error {
 }
 
 func (s *studentAchievementService) Submit(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
-
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
-	}
-
-	if ref.Status != "draft" {
-		return helper.Error(c, 400, "only draft can be submitted")
-	}
-
-	now := time.Now()
-
-	if err := s.repo.UpdateStatusSubmitted(c.Context(), refID, now); err != nil {
-		return helper.Error(c, 500, "failed update status")
-	}
-
-	err = s.mongo.PushHistoryByHexID(
-		c.Context(),
-		ref.MongoAchievementID,
-		"submitted",
-	)
-	if err != nil {
-		return helper.Error(c, 500, "failed update history")
+	ref, status, msg := s.workflow.Transition(c, workflow.Submit, "")
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	return helper.Success(c, "submitted")
+	return helper.Success(c, ref.Status)
 }
 
 func (s *studentAchievementService) GetMyAchievements(c *fiber.Ctx) error {
@@ -217,52 +252,32 @@ func (s *studentAchievementService) GetMyAchievements(c *fiber.Ctx) error {
 }
 
 func (s *studentAchievementService) GetDetail(c *fiber.Ctx) error {
-	refID := c.Params("id")
-
-	uid, err := uuid.Parse(refID)
-	if err != nil {
-		return helper.Error(c, 400, "invalid id")
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	ref, err := s.repo.FindByID(c.Context(), uid)
+	detail, err := s.mongo.FindByHexID(c.Context(), ref.MongoAchievementID)
 	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
-	}
-
-	user := c.Locals("user").(models.Users)
-	student, _ := s.studentRepo.FindByUserID(c.Context(), user.ID.String())
-	if ref.StudentID != student.ID {
-		return helper.Error(c, 403, "forbidden: this achievement is not yours")
+		return helper.Error(c, 500, "failed load mongo detail")
 	}
 
-	detail, err := s.mongo.FindByHexID(c.Context(), ref.MongoAchievementID)
+	approvals, err := s.workflow.Approvals(c.Context(), ref)
 	if err != nil {
-		return helper.Error(c, 500, "failed load mongo detail")
+		return helper.Error(c, 500, "failed load approval stages")
 	}
 
 	return helper.Success(c, fiber.Map{
 		"reference": ref,
 		"detail":    detail,
+		"approvals": approvals,
 	})
 }
 
 func (s *studentAchievementService) GetHistory(c *fiber.Ctx) error {
-	refID := c.Params("id")
-
-	uid, err := uuid.Parse(refID)
-	if err != nil {
-		return helper.Error(c, 400, "invalid id")
-	}
-
-	ref, err := s.repo.FindByID(c.Context(), uid)
-	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
-	}
-
-	user := c.Locals("user").(models.Users)
-	student, _ := s.studentRepo.FindByUserID(c.Context(), user.ID.String())
-	if ref.StudentID != student.ID {
-		return helper.Error(c, 403, "forbidden")
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
 	detail, err := s.mongo.FindByHexID(c.Context(), ref.MongoAchievementID)
@@ -278,21 +293,15 @@ func (s *studentAchievementService) GetHistory(c *fiber.Ctx) error {
 }
 
 func (s *studentAchievementService) UploadAttachment(c *fiber.Ctx) error {
-	refID := uuid.MustParse(c.Params("id"))
 	user := c.Locals("user").(models.Users)
 
-	ref, err := s.repo.FindByID(c.Context(), refID)
-	if err != nil {
-		return helper.Error(c, 404, "achievement not found")
-	}
-
-	student, _ := s.studentRepo.FindByUserID(c.Context(), user.ID.String())
-	if ref.StudentID != student.ID {
-		return helper.Error(c, 403, "forbidden")
+	ref, status, msg := authorizeAchievement(c, s.repo, s.policies, policy.ActionUpload)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
-	if ref.Status != "draft" {
-		return helper.Error(c, 400, "only draft can upload attachments")
+	if !workflow.Editable(ref.Status) {
+		return helper.Error(c, 400, "only draft, revision-requested or rejected achievements can upload attachments")
 	}
 
 	file, err := c.FormFile("file")
@@ -313,6 +322,10 @@ func (s *studentAchievementService) UploadAttachment(c *fiber.Ctx) error {
 	filename := uuid.New().String() + ext
 	fullPath := baseDir + "/" + filename
 
+	if err := s.versions.EnsureBaseline(c.Context(), ref.MongoAchievementID); err != nil {
+		return helper.Error(c, 500, "failed record version")
+	}
+
 	if err := c.SaveFile(file, fullPath); err != nil {
 		return helper.Error(c, 500, "failed save file")
 	}
@@ -331,11 +344,90 @@ func (s *studentAchievementService) UploadAttachment(c *fiber.Ctx) error {
 		},
 	}
 
-	if err := s.mongo.UpdateByHexID(c.Context(), ref.MongoAchievementID, update); err != nil {
-		return helper.Error(c, 500, "failed update mongo")
+	version, failMsg, err := s.writeEditable(c, ref, models.VersionAttachmentAdded, update)
+	if err != nil {
+		os.Remove(fullPath)
+	}
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return helper.Error(c, 409, "only draft, revision-requested or rejected achievements can upload attachments")
+	}
+	if err != nil {
+		return helper.Error(c, 500, failMsg)
 	}
 
 	return helper.Success(c, fiber.Map{
-		"file": filename,
+		"file":    filename,
+		"version": version.Version,
 	})
 }
+
+// writeEditable menjalankan update dokumen hanya selama prestasi masih bisa
+// diedit (row dikunci, jadi submit menunggu), lalu menyimpan dokumen hasil
+// update sebagai versi baru. failMsg berisi pesan untuk error selain
+// repository.ErrStatusChanged.
+func (s *studentAchievementService) writeEditable(
+	c *fiber.Ctx,
+	ref models.AchievementRef,
+	change string,
+	update bson.M,
+) (models.AchievementVersion, string, error) {
+	user := c.Locals("user").(models.Users)
+
+	var version models.AchievementVersion
+	failMsg := "failed record version"
+
+	err := s.repo.WhileStatus(c.Context(), ref.ID, workflow.Editable, func() error {
+		var err error
+		version, err = s.versions.Write(c.Context(), ref.MongoAchievementID, change, user.ID.String(), func() (models.AchievementSnapshot, error) {
+			updated, err := s.mongo.UpdateAndFindByHexID(c.Context(), ref.MongoAchievementID, update)
+			if err != nil {
+				failMsg = "failed update achievement"
+				return models.AchievementSnapshot{}, err
+			}
+			return updated.Snapshot(), nil
+		})
+		return err
+	})
+	if err != nil && !errors.Is(err, repository.ErrStatusChanged) {
+		fmt.Println("ERROR write achievement:", err)
+	}
+
+	return version, failMsg, err
+}
+
+// GetSubmissions menampilkan semua round submit beserta catatan reviewer.
+func (s *studentAchievementService) GetSubmissions(c *fiber.Ctx) error {
+	rounds, status, msg := s.workflow.Submissions(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, rounds)
+}
+
+func (s *studentAchievementService) GetVersions(c *fiber.Ctx) error {
+	list, status, msg := s.versions.List(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, list)
+}
+
+func (s *studentAchievementService) GetVersion(c *fiber.Ctx) error {
+	version, status, msg := s.versions.Get(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, version)
+}
+
+func (s *studentAchievementService) DiffVersions(c *fiber.Ctx) error {
+	diff, status, msg := s.versions.Diff(c)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
+
+	return helper.Success(c, diff)
+}
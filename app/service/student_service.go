@@ -2,6 +2,7 @@ package service
 
 import (
 	"uas/app/models"
+	"uas/app/policy"
 	"uas/app/repository"
 	"uas/helper"
 
@@ -20,13 +21,17 @@ type StudentService interface {
 type studentService struct {
 	repo            repository.StudentRepository
 	achievementRepo repository.AchievementRepository
+	lecturers       repository.LecturerRepository
+	policies        *policy.Resolver
 }
 
 func NewStudentService(
 	repo repository.StudentRepository,
 	achievementRepo repository.AchievementRepository,
+	lecturers repository.LecturerRepository,
+	policies *policy.Resolver,
 ) StudentService {
-	return &studentService{repo, achievementRepo}
+	return &studentService{repo, achievementRepo, lecturers, policies}
 }
 
 func (s *studentService) CreateProfile(c *fiber.Ctx) error {
@@ -41,14 +46,23 @@ func (s *studentService) CreateProfile(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid body")
 	}
 
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid user_id")
+	}
+
 	student := models.Student{
 		ID:           uuid.New(),
-		UserID:       uuid.MustParse(req.UserID),
+		UserID:       userID,
 		StudentID:    req.StudentID,
 		ProgramStudy: req.ProgramStudy,
 		AcademicYear: req.AcademicYear,
 	}
 
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		student.OrgUnitID = &scope.RootID
+	}
+
 	if err := s.repo.Create(c.Context(), student); err != nil {
 		return helper.Error(c, 500, "failed create student profile")
 	}
@@ -65,28 +79,18 @@ func (s *studentService) GetAll(c *fiber.Ctx) error {
 }
 
 func (s *studentService) GetDetail(c *fiber.Ctx) error {
-	id := uuid.MustParse(c.Params("id"))
-	user := c.Locals("user").(models.Users)
-
-	student, err := s.repo.FindByID(c.Context(), id)
-	if err != nil {
-		return helper.Error(c, 404, "mahasiswa tidak ditemukan")
-	}
-
-	if student.UserID != user.ID {
-		return helper.Error(c, 403, "forbidden")
+	student, status, msg := authorizeStudent(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
 	return helper.Success(c, student)
 }
 
 func (s *studentService) GetMyAchievements(c *fiber.Ctx) error {
-	id := uuid.MustParse(c.Params("id"))
-	user := c.Locals("user").(models.Users)
-
-	student, _ := s.repo.FindByID(c.Context(), id)
-	if student.UserID != user.ID {
-		return helper.Error(c, 403, "forbidden")
+	student, status, msg := authorizeStudent(c, s.repo, s.policies, policy.ActionRead)
+	if status != 0 {
+		return helper.Error(c, status, msg)
 	}
 
 	list, err := s.achievementRepo.FindByStudent(c.Context(), student.ID)
@@ -98,7 +102,10 @@ func (s *studentService) GetMyAchievements(c *fiber.Ctx) error {
 }
 
 func (s *studentService) UpdateAdvisor(c *fiber.Ctx) error {
-	studentID := uuid.MustParse(c.Params("id"))
+	student, status, msg := authorizeStudent(c, s.repo, s.policies, policy.ActionManage)
+	if status != 0 {
+		return helper.Error(c, status, msg)
+	}
 
 	var req struct {
 		AdvisorID uuid.UUID `json:"advisor_id"`
@@ -112,11 +119,13 @@ func (s *studentService) UpdateAdvisor(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "advisor_id required")
 	}
 
-	if _, err := s.repo.FindByID(c.Context(), studentID); err != nil {
-		return helper.Error(c, 404, "mahasiswa tidak ditemukan")
+	// admin unit hanya boleh memilih dosen wali dari unit-nya sendiri
+	advisor, err := s.lecturers.FindByID(c.Context(), req.AdvisorID)
+	if err != nil || !inOrgScope(c.Context(), advisor.OrgUnitID) {
+		return helper.Error(c, 404, "dosen wali tidak ditemukan")
 	}
 
-	if err := s.repo.UpdateAdvisor(c.Context(), studentID, req.AdvisorID); err != nil {
+	if err := s.repo.UpdateAdvisor(c.Context(), student.ID, req.AdvisorID); err != nil {
 		return helper.Error(c, 500, "gagal update advisor")
 	}
 
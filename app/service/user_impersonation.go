@@ -0,0 +1,105 @@
+package service
+
+import (
+	"time"
+	"uas/app/models"
+	"uas/app/repository"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const impersonationTTL = 15 * time.Minute
+
+// Impersonate menerbitkan access token singkat yang bertindak sebagai user
+// target. Admin asli disimpan di claim "act" (RFC 8693) sehingga middleware
+// dan audit log tahu kedua identitas. Tidak ada refresh token / session.
+func (s *adminUserService) Impersonate(c *fiber.Ctx) error {
+	admin := c.Locals("user").(models.Users)
+
+	if c.Locals("serviceAccount") != nil {
+		return helper.Error(c, 403, "service accounts cannot impersonate users")
+	}
+
+	// token impersonation membawa scope milik user target, bukan scope admin;
+	// admin unit bisa keluar dari unit-nya lewat user tanpa scope
+	if _, scoped := repository.OrgScopeFromContext(c.Context()); scoped {
+		return helper.Error(c, 403, "unit-scoped administrators cannot impersonate users")
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	if id == admin.ID {
+		return helper.Error(c, 400, "cannot impersonate yourself")
+	}
+
+	target, err := s.repo.FindByID(c.Context(), id.String())
+	if err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
+	if !target.IsActive {
+		return helper.Error(c, 400, "user is not active")
+	}
+
+	// admin lain tidak boleh di-impersonate supaya tidak bisa naik hak akses
+	perms, err := s.repo.GetUserPermissions(c.Context(), id.String())
+	if err != nil {
+		return helper.Error(c, 500, "failed load permissions")
+	}
+	for _, p := range perms {
+		if p == "user:manage" {
+			return helper.Error(c, 403, "cannot impersonate an administrator")
+		}
+	}
+
+	exp := time.Now().Add(impersonationTTL)
+
+	token, err := s.keys.Sign(helper.TokenAccess, jwt.MapClaims{
+		"id":     target.ID.String(),
+		"roleId": target.RoleID.String(),
+		"jti":    uuid.NewString(),
+		"ver":    target.TokenVersion,
+		"act": map[string]interface{}{
+			"sub":      admin.ID.String(),
+			"username": admin.Username,
+			"ver":      admin.TokenVersion,
+		},
+		"exp": exp.Unix(),
+	})
+	if err != nil {
+		return helper.Error(c, 500, "failed to create token")
+	}
+
+	for _, e := range []struct {
+		userID uuid.UUID
+		detail string
+	}{
+		{target.ID, "impersonated by admin " + admin.Username + " (" + admin.ID.String() + ")"},
+		{admin.ID, "started impersonating " + target.Username + " (" + target.ID.String() + ")"},
+	} {
+		userID := e.userID
+		err := s.events.Record(c.Context(), models.SecurityEvent{
+			ID:        uuid.New(),
+			UserID:    &userID,
+			EventType: "impersonation_started",
+			IP:        c.IP(),
+			UserAgent: c.Get("User-Agent"),
+			Detail:    e.detail,
+		})
+		if err != nil {
+			return helper.Error(c, 500, "failed to record impersonation event")
+		}
+	}
+
+	return helper.Success(c, fiber.Map{
+		"token":     token,
+		"expiresAt": exp,
+		"user":      target,
+	})
+}
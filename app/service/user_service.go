@@ -1,13 +1,13 @@
 package service
 
 import (
+	"strconv"
 	"uas/app/models"
 	"uas/app/repository"
 	"uas/helper"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AdminUserService interface {
@@ -17,14 +17,30 @@ type AdminUserService interface {
 	Update(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
 	UpdateRole(c *fiber.Ctx) error
+	Unlock(c *fiber.Ctx) error
+	GetSecurityEvents(c *fiber.Ctx) error
+	Impersonate(c *fiber.Ctx) error
+	GetAuditLogs(c *fiber.Ctx) error
 }
 
 type adminUserService struct {
-	repo repository.AdminUserRepository
+	repo     repository.AdminUserRepository
+	events   repository.SecurityEventRepository
+	audits   repository.AuditLogRepository
+	keys     *helper.KeySet
+	hasher   helper.PasswordHasher
+	orgUnits repository.OrgUnitRepository
 }
 
-func NewAdminUserService(repo repository.AdminUserRepository) AdminUserService {
-	return &adminUserService{repo}
+func NewAdminUserService(
+	repo repository.AdminUserRepository,
+	events repository.SecurityEventRepository,
+	audits repository.AuditLogRepository,
+	keys *helper.KeySet,
+	hasher helper.PasswordHasher,
+	orgUnits repository.OrgUnitRepository,
+) AdminUserService {
+	return &adminUserService{repo, events, audits, keys, hasher, orgUnits}
 }
 
 func (s *adminUserService) GetAll(c *fiber.Ctx) error {
@@ -56,18 +72,36 @@ func (s *adminUserService) Create(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid body")
 	}
 
-	hash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid roleId")
+	}
+
+	if err := helper.ValidatePassword(req.Password); err != nil {
+		return helper.Error(c, 400, err.Error())
+	}
+
+	hash, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return helper.Error(c, 500, "failed create user")
+	}
 
 	user := models.Users{
 		ID:           uuid.New(),
 		Username:     req.Username,
 		Email:        req.Email,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		FullName:     req.FullName,
-		RoleID:       uuid.MustParse(req.RoleID),
+		RoleID:       roleID,
 		IsActive:     true,
 	}
 
+	// user buatan admin unit ikut unit admin tersebut, jangan sampai
+	// jadi user tanpa scope (= seluruh universitas)
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		user.ScopeOrgUnitID = &scope.RootID
+	}
+
 	if err := s.repo.Create(c.Context(), user); err != nil {
 		return helper.Error(c, 500, "failed create user")
 	}
@@ -89,12 +123,27 @@ func (s *adminUserService) Update(c *fiber.Ctx) error {
 		return helper.Error(c, 400, "invalid body")
 	}
 
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid roleId")
+	}
+
+	current, err := s.repo.FindByID(c.Context(), id)
+	if err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
 	user := models.Users{
-		ID:       uuid.MustParse(id),
+		ID:       current.ID,
 		Username: req.Username,
 		Email:    req.Email,
 		FullName: req.FullName,
-		RoleID:   uuid.MustParse(req.RoleID),
+		RoleID:   roleID,
+	}
+
+	// perubahan role oleh admin unit harus lewat PUT /users/:id/role supaya scope-nya ikut diatur
+	if _, scoped := repository.OrgScopeFromContext(c.Context()); scoped && user.RoleID != current.RoleID {
+		return helper.Error(c, 403, "use the role endpoint to change roles")
 	}
 
 	if err := s.repo.Update(c.Context(), user); err != nil {
@@ -107,6 +156,10 @@ func (s *adminUserService) Update(c *fiber.Ctx) error {
 func (s *adminUserService) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	if _, err := s.repo.FindByID(c.Context(), id); err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
 	if err := s.repo.SoftDelete(c.Context(), id); err != nil {
 		return helper.Error(c, 500, "failed to delete user")
 	}
@@ -118,18 +171,133 @@ func (s *adminUserService) UpdateRole(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	var req struct {
-		RoleID string `json:"roleId"`
+		RoleID    string `json:"roleId"`
+		OrgUnitID string `json:"orgUnitId"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
 		return helper.Error(c, 400, "invalid body")
 	}
 
-	roleUUID := uuid.MustParse(req.RoleID)
+	roleUUID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return helper.Error(c, 400, "invalid roleId")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id); err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
+	// orgUnitId kosong = role berlaku untuk seluruh universitas
+	var unitID *uuid.UUID
+	if req.OrgUnitID != "" {
+		parsed, err := uuid.Parse(req.OrgUnitID)
+		if err != nil {
+			return helper.Error(c, 400, "invalid orgUnitId")
+		}
+		if _, err := s.orgUnits.FindByID(c.Context(), parsed); err != nil {
+			return helper.Error(c, 404, "org unit not found")
+		}
+		unitID = &parsed
+	}
+
+	// admin unit hanya boleh membagikan scope di dalam unit-nya sendiri
+	if scope, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		if unitID == nil {
+			unitID = &scope.RootID
+		}
+		if !scope.Contains(unitID) {
+			return helper.Error(c, 403, "org unit is outside your scope")
+		}
+	}
 
-	if err := s.repo.UpdateRole(c.Context(), id, roleUUID); err != nil {
+	if err := s.repo.UpdateRole(c.Context(), id, roleUUID, unitID); err != nil {
 		return helper.Error(c, 500, "failed to update role")
 	}
 
 	return helper.Success(c, "role updated")
 }
+
+func (s *adminUserService) Unlock(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id.String()); err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
+	if err := s.repo.Unlock(c.Context(), id.String()); err != nil {
+		return helper.Error(c, 500, "failed to unlock user")
+	}
+
+	admin := c.Locals("user").(models.Users)
+
+	err = s.events.Record(c.Context(), models.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &id,
+		EventType: "account_unlocked",
+		IP:        c.IP(),
+		UserAgent: c.Get("User-Agent"),
+		Detail:    "unlocked by admin " + admin.Username + " (" + admin.ID.String() + ")",
+	})
+	if err != nil {
+		return helper.Error(c, 500, "failed to record unlock event")
+	}
+
+	return helper.Success(c, "user unlocked")
+}
+
+func (s *adminUserService) GetSecurityEvents(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id.String()); err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	limit = clampLimit(limit, 500)
+
+	events, err := s.events.FindByUser(c.Context(), id, limit)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch security events")
+	}
+
+	return helper.Success(c, events)
+}
+
+func (s *adminUserService) GetAuditLogs(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return helper.Error(c, 400, "invalid id")
+	}
+
+	if _, err := s.repo.FindByID(c.Context(), id.String()); err != nil {
+		return helper.Error(c, 404, "user not found")
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "100"))
+	limit = clampLimit(limit, 500)
+
+	logs, err := s.audits.FindByUser(c.Context(), id, limit)
+	if err != nil {
+		return helper.Error(c, 500, "failed fetch audit logs")
+	}
+
+	return helper.Success(c, logs)
+}
+
+// clampLimit menjaga ?limit tetap di 1..max (nilai negatif membuat query gagal).
+func clampLimit(limit int, max int) int {
+	if limit < 1 {
+		return 1
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
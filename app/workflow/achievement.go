@@ -0,0 +1,187 @@
+// Package workflow mendefinisikan state machine prestasi: status yang ada,
+// transisi yang diizinkan, siapa yang boleh memicunya (lewat policy action)
+// dan efek sampingnya terhadap achievement_references.
+package workflow
+
+import (
+	"time"
+	"uas/app/models"
+	"uas/app/policy"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Draft             = "draft"
+	Submitted         = "submitted"
+	Verified          = "verified"
+	Rejected          = "rejected"
+	RevisionRequested = "revision_requested"
+)
+
+type Event string
+
+const (
+	Submit          Event = "submit"
+	Verify          Event = "verify"
+	Reject          Event = "reject"
+	RequestRevision Event = "request_revision"
+	// ApproveStage: verify pada stage approval chain yang belum terakhir.
+	// Tidak dipicu langsung; achievementWorkflow mengubah Verify menjadi
+	// ApproveStage selama masih ada stage berikutnya.
+	ApproveStage Event = "approve_stage"
+)
+
+// Effect adalah efek samping transisi terhadap kolom achievement_references.
+type Effect int
+
+const (
+	// SetSubmittedAt mengisi submitted_at.
+	SetSubmittedAt Effect = 1 << iota
+	// SetReviewer mengisi verified_at, verified_by dan delegation_id.
+	SetReviewer
+	// SetRejectionNote menyimpan catatan ke rejection_note.
+	SetRejectionNote
+	// SetRevisionNote menyimpan feedback ke revision_note.
+	SetRevisionNote
+	// ClearReview menghapus hasil review sebelumnya (submit ulang setelah revisi).
+	ClearReview
+	// OpenRound menyimpan round submission baru beserta snapshot isinya.
+	OpenRound
+	// CloseRound mengisi hasil review pada round yang sedang terbuka.
+	CloseRound
+	// RecordApproval menandai stage approval chain yang aktif sudah disetujui.
+	RecordApproval
+)
+
+type Transition struct {
+	Event Event
+	From  []string
+	To    string
+	// Action adalah policy action yang harus dimiliki actor:
+	// ActionSubmit untuk pemilik, ActionVerify dst. untuk reviewer.
+	Action       policy.Action
+	RequiresNote bool
+	Effects      Effect
+}
+
+var transitions = []Transition{
+	{
+		Event:   Submit,
+		From:    []string{Draft, RevisionRequested, Rejected},
+		To:      Submitted,
+		Action:  policy.ActionSubmit,
+		Effects: SetSubmittedAt | ClearReview | OpenRound,
+	},
+	{
+		Event:   Verify,
+		From:    []string{Submitted},
+		To:      Verified,
+		Action:  policy.ActionVerify,
+		Effects: SetReviewer | CloseRound | RecordApproval,
+	},
+	{
+		Event:   ApproveStage,
+		From:    []string{Submitted},
+		To:      Submitted,
+		Action:  policy.ActionVerify,
+		Effects: RecordApproval,
+	},
+	{
+		Event:   Reject,
+		From:    []string{Submitted},
+		To:      Rejected,
+		Action:  policy.ActionReject,
+		Effects: SetReviewer | SetRejectionNote | CloseRound,
+	},
+	{
+		Event:        RequestRevision,
+		From:         []string{Submitted},
+		To:           RevisionRequested,
+		Action:       policy.ActionRequestRevision,
+		RequiresNote: true,
+		Effects:      SetReviewer | SetRevisionNote | CloseRound,
+	},
+}
+
+// Lookup mengembalikan definisi transisi untuk event tersebut.
+func Lookup(event Event) (Transition, bool) {
+	for _, t := range transitions {
+		if t.Event == event {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// Allows bernilai true kalau transisi boleh dijalankan dari status from.
+func (t Transition) Allows(from string) bool {
+	for _, s := range t.From {
+		if s == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Change berisi siapa yang memicu transisi beserta catatannya.
+type Change struct {
+	By           uuid.UUID
+	DelegationID *uuid.UUID
+	Note         string
+	At           time.Time
+}
+
+// Apply mengembalikan salinan ref setelah transisi dan efek sampingnya.
+func (t Transition) Apply(ref models.AchievementRef, change Change) models.AchievementRef {
+	ref.Status = t.To
+	ref.UpdatedAt = change.At
+
+	if t.Effects&ClearReview != 0 {
+		ref.VerifiedAt = nil
+		ref.VerifiedBy = nil
+		ref.DelegationID = nil
+		ref.RejectionNote = nil
+		ref.RevisionNote = nil
+	}
+
+	if t.Effects&SetSubmittedAt != 0 {
+		at := change.At
+		ref.SubmittedAt = &at
+	}
+
+	if t.Effects&SetReviewer != 0 {
+		at, by := change.At, change.By
+		ref.VerifiedAt = &at
+		ref.VerifiedBy = &by
+		ref.DelegationID = change.DelegationID
+	}
+
+	if t.Effects&SetRejectionNote != 0 {
+		note := change.Note
+		ref.RejectionNote = &note
+	}
+
+	if t.Effects&SetRevisionNote != 0 {
+		note := change.Note
+		ref.RevisionNote = &note
+	}
+
+	return ref
+}
+
+// Editable: isi prestasi dan lampiran hanya boleh diubah pemiliknya
+// selama masih draft, diminta revisi, atau ditolak (untuk submit ulang).
+func Editable(status string) bool {
+	return status == Draft || status == RevisionRequested || status == Rejected
+}
+
+// Returned: round yang berakhir dengan prestasi dikembalikan ke mahasiswa.
+func Returned(outcome string) bool {
+	return outcome == Rejected || outcome == RevisionRequested
+}
+
+// Deletable: hanya draft yang boleh dihapus.
+func Deletable(status string) bool {
+	return status == Draft
+}
@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"uas/app/models"
+)
+
+// Diff mengembalikan field yang berbeda antara dua snapshot.
+func Diff(old, new models.AchievementSnapshot) []models.FieldChange {
+	changes := []models.FieldChange{}
+
+	add := func(field string, o, n string) {
+		if o != n {
+			changes = append(changes, models.FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+
+	add("title", old.Title, new.Title)
+	add("description", old.Description, new.Description)
+	add("category", old.Category, new.Category)
+	add("level", old.Level, new.Level)
+	add("eventDate", old.EventDate, new.EventDate)
+
+	if !sameStrings(old.Attachments, new.Attachments) {
+		changes = append(changes, models.FieldChange{
+			Field: "attachments",
+			Old:   old.Attachments,
+			New:   new.Attachments,
+		})
+	}
+
+	return changes
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS refresh_token_families (
+    id              UUID PRIMARY KEY,
+    user_id         UUID NOT NULL REFERENCES users(id),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at      TIMESTAMP,
+    revoked_reason  VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_families_user_id ON refresh_token_families (user_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    jti         UUID PRIMARY KEY,
+    family_id   UUID NOT NULL REFERENCES refresh_token_families(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id),
+    issued_at   TIMESTAMP NOT NULL,
+    expires_at  TIMESTAMP NOT NULL,
+    used_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id          UUID PRIMARY KEY,
+    user_id     UUID REFERENCES users(id),
+    event_type  VARCHAR(50) NOT NULL,
+    ip          VARCHAR(64),
+    user_agent  TEXT,
+    detail      TEXT,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id, created_at);
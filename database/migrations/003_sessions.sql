@@ -0,0 +1,4 @@
+ALTER TABLE refresh_token_families
+    ADD COLUMN IF NOT EXISTS ip            VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS user_agent    TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_used_at  TIMESTAMP NOT NULL DEFAULT NOW();
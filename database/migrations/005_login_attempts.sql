@@ -0,0 +1,15 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_login_count    INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_failed_login_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS locked_until          TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id          BIGSERIAL PRIMARY KEY,
+    username    VARCHAR(100) NOT NULL,
+    ip          VARCHAR(64) NOT NULL,
+    success     BOOLEAN NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts (ip, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_username ON login_attempts (username, created_at);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id         UUID PRIMARY KEY REFERENCES users(id),
+    secret          VARCHAR(64) NOT NULL,
+    enabled         BOOLEAN NOT NULL DEFAULT false,
+    enabled_at      TIMESTAMP,
+    last_used_step  BIGINT,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id),
+    code_hash   VARCHAR(64) NOT NULL,
+    used_at     TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
+
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS mfa_required BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id           UUID PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL UNIQUE,
+    description  TEXT NOT NULL DEFAULT '',
+    is_active    BOOLEAN NOT NULL DEFAULT true,
+    created_by   UUID NOT NULL REFERENCES users(id),
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                  UUID PRIMARY KEY,
+    service_account_id  UUID NOT NULL REFERENCES service_accounts(id),
+    prefix              VARCHAR(16) NOT NULL UNIQUE,
+    key_hash            VARCHAR(64) NOT NULL,
+    scopes              TEXT[] NOT NULL DEFAULT '{}',
+    expires_at          TIMESTAMP,
+    last_used_at        TIMESTAMP,
+    revoked_at          TIMESTAMP,
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_service_account_id ON api_keys (service_account_id);
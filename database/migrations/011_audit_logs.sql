@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id               UUID PRIMARY KEY,
+    user_id          UUID NOT NULL REFERENCES users(id),
+    impersonator_id  UUID REFERENCES users(id),
+    method           VARCHAR(10) NOT NULL,
+    path             TEXT NOT NULL,
+    status           INT NOT NULL,
+    ip               VARCHAR(64),
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator_id ON audit_logs (impersonator_id, created_at);
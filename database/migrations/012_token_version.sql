@@ -0,0 +1,3 @@
+-- dinaikkan setiap kali user dinonaktifkan, ganti role atau ganti password;
+-- token dengan claim "ver" yang lebih lama langsung ditolak
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
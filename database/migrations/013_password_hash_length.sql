@@ -0,0 +1,2 @@
+-- hash argon2id (format PHC) lebih panjang dari 60 karakter bcrypt
+ALTER TABLE users ALTER COLUMN password_hash TYPE VARCHAR(255);
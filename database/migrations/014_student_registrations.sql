@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS student_registrations (
+    id                 UUID PRIMARY KEY,
+    full_name          VARCHAR(100) NOT NULL,
+    email              VARCHAR(100) NOT NULL,
+    student_id         VARCHAR(20) NOT NULL,
+    program_study      VARCHAR(100) NOT NULL,
+    academic_year      VARCHAR(10) NOT NULL,
+    password_hash      VARCHAR(255) NOT NULL,
+    status             VARCHAR(30) NOT NULL DEFAULT 'pending_verification',
+    email_verified_at  TIMESTAMP,
+    reviewed_by        UUID REFERENCES users(id),
+    reviewed_at        TIMESTAMP,
+    reject_reason      TEXT,
+    user_id            UUID REFERENCES users(id),
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- satu pendaftaran aktif per email / NIM
+CREATE UNIQUE INDEX IF NOT EXISTS uq_student_registrations_active_email
+    ON student_registrations (LOWER(email))
+    WHERE status IN ('pending_verification', 'pending_approval');
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_student_registrations_active_student_id
+    ON student_registrations (student_id)
+    WHERE status IN ('pending_verification', 'pending_approval');
+
+CREATE INDEX IF NOT EXISTS idx_student_registrations_status ON student_registrations (status, created_at);
@@ -0,0 +1,23 @@
+-- role dan permission yang dipakai langsung oleh kode tidak boleh dihapus / diganti nama
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE roles SET is_system = true
+WHERE name IN ('Admin', 'Mahasiswa', 'Dosen Wali');
+
+UPDATE permissions SET is_system = true
+WHERE name IN (
+    'user:manage',
+    'achievement:create',
+    'achievement:update',
+    'achievement:delete',
+    'achievement:submit',
+    'achievement:upload',
+    'achievement:read_advisee',
+    'achievement:verify',
+    'achievement:reject'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_roles_name ON roles (LOWER(name));
+CREATE UNIQUE INDEX IF NOT EXISTS uq_permissions_name ON permissions (name);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_role_permissions ON role_permissions (role_id, permission_id);
@@ -0,0 +1,4 @@
+-- admin departemen: akses prestasi mahasiswa yang dosen walinya di departemen yang sama
+INSERT INTO permissions (id, name, description, is_system)
+VALUES (gen_random_uuid(), 'achievement:department', 'Kelola prestasi mahasiswa satu departemen', true)
+ON CONFLICT (name) DO NOTHING;
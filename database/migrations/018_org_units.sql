@@ -0,0 +1,20 @@
+-- struktur organisasi: universitas -> fakultas -> departemen / prodi
+CREATE TABLE IF NOT EXISTS org_units (
+    id UUID PRIMARY KEY,
+    parent_id UUID REFERENCES org_units(id) ON DELETE RESTRICT,
+    name VARCHAR(150) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('university', 'faculty', 'department')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_units_parent ON org_units (parent_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_org_units_name ON org_units (COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'::uuid), LOWER(name));
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS org_unit_id UUID REFERENCES org_units(id) ON DELETE SET NULL;
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS org_unit_id UUID REFERENCES org_units(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_students_org_unit ON students (org_unit_id);
+CREATE INDEX IF NOT EXISTS idx_lecturers_org_unit ON lecturers (org_unit_id);
+
+-- cakupan role admin; NULL berarti seluruh universitas
+ALTER TABLE users ADD COLUMN IF NOT EXISTS scope_org_unit_id UUID REFERENCES org_units(id) ON DELETE RESTRICT;
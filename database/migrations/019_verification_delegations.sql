@@ -0,0 +1,21 @@
+-- dosen wali yang cuti bisa mendelegasikan verifikasi ke dosen lain untuk sementara
+CREATE TABLE IF NOT EXISTS verification_delegations (
+    id            UUID PRIMARY KEY,
+    delegator_id  UUID NOT NULL REFERENCES lecturers(id),
+    delegate_id   UUID NOT NULL REFERENCES lecturers(id),
+    starts_at     TIMESTAMP NOT NULL,
+    ends_at       TIMESTAMP NOT NULL,
+    reason        TEXT,
+    created_by    UUID NOT NULL REFERENCES users(id),
+    revoked_at    TIMESTAMP,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at),
+    CHECK (delegator_id <> delegate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegate ON verification_delegations (delegate_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegator ON verification_delegations (delegator_id, ends_at);
+
+-- delegasi yang dipakai saat verify / reject (NULL = oleh dosen wali / admin langsung)
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS delegation_id UUID REFERENCES verification_delegations(id);
@@ -0,0 +1,4 @@
+-- status baru: dikembalikan ke mahasiswa untuk diperbaiki
+ALTER TYPE achievement_status ADD VALUE IF NOT EXISTS 'revision_requested';
+
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS revision_note TEXT;
@@ -0,0 +1,16 @@
+-- setiap submit membuka satu round; verify / reject / revisi menutupnya
+CREATE TABLE IF NOT EXISTS achievement_submissions (
+    id              UUID PRIMARY KEY,
+    achievement_id  UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    round           INT NOT NULL,
+    snapshot        JSONB NOT NULL,
+    changes         JSONB NOT NULL DEFAULT '[]',
+    submitted_by    UUID NOT NULL REFERENCES users(id),
+    submitted_at    TIMESTAMP NOT NULL,
+    outcome         VARCHAR(30),
+    reviewed_by     UUID REFERENCES users(id),
+    reviewed_at     TIMESTAMP,
+    delegation_id   UUID REFERENCES verification_delegations(id),
+    note            TEXT,
+    UNIQUE (achievement_id, round)
+);
@@ -0,0 +1,48 @@
+-- rantai persetujuan bertingkat per level / kategori prestasi
+-- (mis. internasional: dosen wali -> kepala departemen -> kemahasiswaan)
+CREATE TABLE IF NOT EXISTS approval_chains (
+    id          UUID PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    level       VARCHAR(50),  -- NULL = semua level
+    category    VARCHAR(50),  -- NULL = semua kategori
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_approval_chains_match
+    ON approval_chains (COALESCE(LOWER(level), ''), COALESCE(LOWER(category), ''));
+
+CREATE TABLE IF NOT EXISTS approval_stages (
+    id          UUID PRIMARY KEY,
+    chain_id    UUID NOT NULL REFERENCES approval_chains(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    permission  VARCHAR(100) NOT NULL REFERENCES permissions(name),
+    scope       VARCHAR(20) NOT NULL CHECK (scope IN ('advisor', 'department', 'global')),
+    UNIQUE (chain_id, position)
+);
+
+-- salinan stage untuk tiap round submit, supaya perubahan konfigurasi
+-- tidak mengubah prestasi yang sedang berjalan
+CREATE TABLE IF NOT EXISTS achievement_approvals (
+    id              UUID PRIMARY KEY,
+    submission_id   UUID NOT NULL REFERENCES achievement_submissions(id) ON DELETE CASCADE,
+    achievement_id  UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    position        INT NOT NULL,
+    name            VARCHAR(100) NOT NULL,
+    permission      VARCHAR(100) NOT NULL,
+    scope           VARCHAR(20) NOT NULL,
+    approved_by     UUID REFERENCES users(id),
+    approved_at     TIMESTAMP,
+    delegation_id   UUID REFERENCES verification_delegations(id),
+    note            TEXT,
+    UNIQUE (submission_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_approvals_pending
+    ON achievement_approvals (permission, position) WHERE approved_at IS NULL;
+
+INSERT INTO permissions (id, name, description, is_system) VALUES
+    (gen_random_uuid(), 'achievement:approve_department', 'Persetujuan prestasi tingkat kepala departemen', true),
+    (gen_random_uuid(), 'achievement:approve_affairs', 'Persetujuan prestasi tingkat kemahasiswaan', true)
+ON CONFLICT (name) DO NOTHING;
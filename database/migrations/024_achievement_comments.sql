@@ -0,0 +1,28 @@
+-- diskusi antara mahasiswa dan reviewer pada satu prestasi
+CREATE TABLE IF NOT EXISTS achievement_comments (
+    id              UUID PRIMARY KEY,
+    achievement_id  UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    parent_id       UUID REFERENCES achievement_comments(id) ON DELETE CASCADE,
+    author_id       UUID NOT NULL REFERENCES users(id),
+    body            TEXT NOT NULL,
+    internal        BOOLEAN NOT NULL DEFAULT FALSE, -- hanya terlihat oleh reviewer
+    field           VARCHAR(50),                    -- field prestasi yang dikomentari
+    attachment      VARCHAR(255),                   -- nama file lampiran yang dikomentari
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_comments_achievement ON achievement_comments (achievement_id, created_at);
+
+-- isi lama setiap kali komentar diedit / dihapus
+CREATE TABLE IF NOT EXISTS achievement_comment_revisions (
+    id          UUID PRIMARY KEY,
+    comment_id  UUID NOT NULL REFERENCES achievement_comments(id) ON DELETE CASCADE,
+    action      VARCHAR(10) NOT NULL CHECK (action IN ('edited', 'deleted')),
+    body        TEXT NOT NULL,
+    changed_by  UUID NOT NULL REFERENCES users(id),
+    changed_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_comment_revisions_comment ON achievement_comment_revisions (comment_id, changed_at);
@@ -0,0 +1,12 @@
+-- statistik laporan tidak lagi butuh user:manage; role yang selama ini
+-- membaca laporan (pemegang user:manage) otomatis mendapat report:read
+INSERT INTO permissions (id, name, description, is_system) VALUES
+    (gen_random_uuid(), 'report:read', 'Melihat statistik laporan prestasi', true)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT rp.role_id, p.id
+FROM role_permissions rp
+JOIN permissions admin ON admin.id = rp.permission_id AND admin.name = 'user:manage'
+JOIN permissions p ON p.name = 'report:read'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
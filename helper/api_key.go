@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+const apiKeyPrefix = "uas"
+
+// GenerateAPIKey menghasilkan key berbentuk "uas_<prefix>_<secret>".
+// Prefix disimpan apa adanya untuk lookup, key lengkap hanya disimpan hash-nya.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err := RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKeyPrefix + "_" + prefix + "_" + secret, prefix, nil
+}
+
+// ParseAPIKey mengambil prefix lookup dari API key.
+func ParseAPIKey(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || len(parts[1]) != 8 || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
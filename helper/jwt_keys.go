@@ -0,0 +1,272 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeySet menyimpan satu kunci aktif untuk menandatangani token dan
+// beberapa public key untuk verifikasi (rotasi), dipilih lewat header kid.
+type KeySet struct {
+	activeKID string
+	signer    crypto.Signer
+	issuer    string
+	audience  string
+	keys      map[string]verificationKey
+}
+
+// Jenis token yang ditandatangani KeySet. Semuanya memakai kunci yang sama
+// (dipublikasikan lewat JWKS), jadi tiap jenis diberi aud sendiri supaya
+// verifier luar tidak menerima refresh / MFA token sebagai access token.
+const (
+	TokenAccess       = "access"
+	TokenRefresh      = "refresh"
+	TokenMFA          = "mfa"
+	TokenOIDCState    = "oidc_state"
+	TokenRegistration = "registration"
+)
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// LoadKeySetFromEnv membaca semua file *.pem di JWT_KEYS_DIR (kid = nama file
+// tanpa ekstensi). JWT_ACTIVE_KID wajib menunjuk ke private key.
+// JWT_AUDIENCE (default "uas-api") adalah aud access token.
+func LoadKeySetFromEnv() (*KeySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		dir = "./keys"
+	}
+
+	activeKID := os.Getenv("JWT_ACTIVE_KID")
+	if activeKID == "" {
+		return nil, errors.New("JWT_ACTIVE_KID belum di-set di .env")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{
+		activeKID: activeKID,
+		issuer:    os.Getenv("JWT_ISSUER"),
+		audience:  os.Getenv("JWT_AUDIENCE"),
+		keys:      make(map[string]verificationKey),
+	}
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		private, public, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", file, err)
+		}
+
+		method, err := signingMethodFor(public)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", file, err)
+		}
+
+		ks.keys[kid] = verificationKey{method: method, public: public}
+
+		if kid == activeKID {
+			if private == nil {
+				return nil, fmt.Errorf("active key %s must be a private key", kid)
+			}
+			ks.signer = private
+		}
+	}
+
+	if ks.signer == nil {
+		return nil, fmt.Errorf("active key %s not found in %s", activeKID, dir)
+	}
+
+	if ks.audience == "" {
+		ks.audience = "uas-api"
+	}
+
+	return ks, nil
+}
+
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.New("unsupported private key")
+		}
+		return signer, signer.Public(), nil
+
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		return nil, key, err
+
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		return nil, key, err
+	}
+
+	return nil, nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+}
+
+func signingMethodFor(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, errors.New("only RSA and Ed25519 keys are supported")
+}
+
+// Audience mengembalikan aud untuk jenis token: access token memakai
+// audience API, jenis lain diberi akhiran ":<typ>".
+func (k *KeySet) Audience(typ string) string {
+	if typ == TokenAccess {
+		return k.audience
+	}
+	return k.audience + ":" + typ
+}
+
+// Sign menandatangani claims sebagai token jenis typ dengan kunci aktif.
+func (k *KeySet) Sign(typ string, claims jwt.MapClaims) (string, error) {
+	if k.issuer != "" {
+		claims["iss"] = k.issuer
+	}
+	claims["typ"] = typ
+	claims["aud"] = k.Audience(typ)
+
+	token := jwt.NewWithClaims(k.keys[k.activeKID].method, claims)
+	token.Header["kid"] = k.activeKID
+
+	return token.SignedString(k.signer)
+}
+
+// Parse memverifikasi token jenis typ (aud dan claim typ) dengan public
+// key sesuai kid dan mengembalikan claims kalau valid.
+func (k *KeySet) Parse(typ string, tokenStr string) (jwt.MapClaims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{
+			jwt.SigningMethodRS256.Alg(),
+			jwt.SigningMethodEdDSA.Alg(),
+		}),
+		jwt.WithAudience(k.Audience(typ)),
+	}
+	if k.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(k.issuer))
+	}
+
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("signing method does not match key")
+		}
+
+		return key.public, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+
+	if t, _ := claims["typ"].(string); t != typ {
+		return nil, errors.New("invalid token type")
+	}
+
+	return claims, nil
+}
+
+// JWKS mengembalikan semua public key dalam format JSON Web Key Set.
+func (k *KeySet) JWKS() map[string]any {
+	kids := make([]string, 0, len(k.keys))
+	for kid := range k.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]map[string]any, 0, len(kids))
+	for _, kid := range kids {
+		key := k.keys[kid]
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]any{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"alg": key.method.Alg(),
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]any{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"kid": kid,
+				"use": "sig",
+				"alg": key.method.Alg(),
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return map[string]any{"keys": keys}
+}
+
+// TokenVersionMatches membandingkan claim "ver" dengan users.token_version.
+// Token lama tanpa claim "ver" dianggap versi 0.
+func TokenVersionMatches(claims jwt.MapClaims, version int) bool {
+	ver, _ := claims["ver"].(float64)
+	return int(ver) == version
+}
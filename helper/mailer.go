@@ -0,0 +1,80 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+// NewMailerFromEnv memilih mailer dari MAILER (smtp | file | log).
+// Default log, cocok untuk development lokal.
+func NewMailerFromEnv() Mailer {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		return &smtpMailer{
+			addr: os.Getenv("SMTP_HOST") + ":" + os.Getenv("SMTP_PORT"),
+			from: os.Getenv("SMTP_FROM"),
+			auth: smtp.PlainAuth("",
+				os.Getenv("SMTP_USER"),
+				os.Getenv("SMTP_PASSWORD"),
+				os.Getenv("SMTP_HOST"),
+			),
+		}
+	case "file":
+		path := os.Getenv("MAIL_FILE")
+		if path == "" {
+			path = "./mail.log"
+		}
+		return &fileMailer{path: path}
+	default:
+		return logMailer{}
+	}
+}
+
+type logMailer struct{}
+
+func (logMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	log.Printf("[MAIL] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+type fileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (m *fileMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "=== %s ===\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), to, subject, body)
+	return err
+}
+
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func (m *smtpMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		m.from, to, subject, body)
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg))
+}
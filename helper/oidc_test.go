@@ -0,0 +1,227 @@
+package helper
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIssuer adalah identity provider minimal: discovery, JWKS dan token
+// endpoint yang memeriksa PKCE seperti provider sungguhan.
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu sync.Mutex
+	// code -> code_challenge & nonce dari authorization request
+	codes map[string]mockGrant
+
+	// override discovery / claim id_token untuk kasus negatif
+	discoveryIssuer string
+	audience        string
+	issuer          string
+	nonce           string
+}
+
+type mockGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockIssuer{t: t, key: key, codes: make(map[string]mockGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := m.server.URL
+		if m.discoveryIssuer != "" {
+			issuer = m.discoveryIssuer
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", m.token)
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// authorize mensimulasikan login user di IdP: menyimpan challenge dan nonce
+// dari authorization URL lalu mengembalikan code.
+func (m *mockIssuer) authorize(authURL string) string {
+	m.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, m.server.URL+"/authorize?") {
+		m.t.Fatalf("authorization URL not from discovery: %s", authURL)
+	}
+
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		m.t.Fatalf("code_challenge_method = %q, want S256", q.Get("code_challenge_method"))
+	}
+
+	code := "code-" + q.Get("state")
+
+	m.mu.Lock()
+	m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	m.mu.Unlock()
+
+	return code
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	grant, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	if !ok || PKCEChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   m.server.URL,
+		"sub":   "user-123",
+		"aud":   r.PostForm.Get("client_id"),
+		"nonce": grant.nonce,
+		"email": "mhs@kampus.ac.id",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+	if m.audience != "" {
+		claims["aud"] = m.audience
+	}
+	if m.issuer != "" {
+		claims["iss"] = m.issuer
+	}
+	if m.nonce != "" {
+		claims["nonce"] = m.nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+}
+
+func (m *mockIssuer) provider() *OIDCProvider {
+	return &OIDCProvider{
+		Issuer:      m.server.URL,
+		ClientID:    "uas-client",
+		RedirectURL: "http://localhost/callback",
+		Scopes:      []string{"openid", "email"},
+		httpClient:  m.server.Client(),
+	}
+}
+
+func TestOIDCExchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(m *mockIssuer)
+		verifier string // kosong = verifier yang benar
+		wantErr  string
+	}{
+		{name: "valid login"},
+		{name: "wrong PKCE verifier", verifier: "not-the-verifier", wantErr: "returned 400"},
+		{name: "nonce mismatch", setup: func(m *mockIssuer) { m.nonce = "replayed" }, wantErr: "nonce mismatch"},
+		{name: "wrong audience", setup: func(m *mockIssuer) { m.audience = "other-client" }, wantErr: "audience"},
+		{name: "wrong issuer", setup: func(m *mockIssuer) { m.issuer = "https://evil.example" }, wantErr: "issuer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			p := m.provider()
+			ctx := context.Background()
+
+			verifier := "verifier-0123456789-0123456789-0123456789"
+			authURL, err := p.AuthCodeURL(ctx, "state1", "nonce1", verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code := m.authorize(authURL)
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			claims, err := p.Exchange(ctx, code, verifier, "nonce1")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if claims["sub"] != "user-123" {
+				t.Fatalf("sub = %v", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockIssuer(t)
+	m.discoveryIssuer = "https://evil.example"
+
+	_, err := m.provider().AuthCodeURL(context.Background(), "s", "n", "v")
+	if err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("AuthCodeURL error = %v, want issuer mismatch", err)
+	}
+}
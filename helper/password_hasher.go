@@ -0,0 +1,146 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher membuat dan memverifikasi hash password. Verify tetap
+// menerima algoritma lama (bcrypt) supaya user lama bisa login, lalu
+// NeedsRehash memberi tahu kapan hash-nya perlu diganti.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash string, password string) bool
+	NeedsRehash(hash string) bool
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	keyLen  uint32
+	saltLen int
+}
+
+type passwordHasher struct {
+	algorithm  string
+	argon2     argon2Params
+	bcryptCost int
+}
+
+// NewPasswordHasherFromEnv membaca PASSWORD_HASH_ALGORITHM (argon2id | bcrypt,
+// default argon2id), ARGON2_MEMORY_KB, ARGON2_TIME, ARGON2_THREADS dan BCRYPT_COST.
+// Default argon2id mengikuti rekomendasi OWASP (19 MiB, t=2, p=1).
+func NewPasswordHasherFromEnv() PasswordHasher {
+	algorithm := strings.ToLower(os.Getenv("PASSWORD_HASH_ALGORITHM"))
+	if algorithm != "bcrypt" {
+		algorithm = "argon2id"
+	}
+
+	return &passwordHasher{
+		algorithm: algorithm,
+		argon2: argon2Params{
+			memory:  uint32(envUint("ARGON2_MEMORY_KB", 19*1024)),
+			time:    uint32(envUint("ARGON2_TIME", 2)),
+			threads: uint8(envUint("ARGON2_THREADS", 1)),
+			keyLen:  32,
+			saltLen: 16,
+		},
+		bcryptCost: int(envUint("BCRYPT_COST", uint64(bcrypt.DefaultCost))),
+	}
+}
+
+func envUint(key string, def uint64) uint64 {
+	if v, err := strconv.ParseUint(os.Getenv(key), 10, 32); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	if h.algorithm == "bcrypt" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, h.argon2.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.argon2
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+
+	// format PHC: $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *passwordHasher) Verify(hash string, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, salt, key, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func (h *passwordHasher) NeedsRehash(hash string) bool {
+	if h.algorithm == "bcrypt" {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost < h.bcryptCost
+	}
+
+	p, _, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return p.memory < h.argon2.memory || p.time < h.argon2.time || p.threads < h.argon2.threads
+}
+
+func decodeArgon2Hash(hash string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+
+	return p, salt, key, nil
+}
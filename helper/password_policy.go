@@ -0,0 +1,88 @@
+package helper
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	MinPasswordLength = 8
+	// MaxPasswordLength membatasi biaya hashing untuk input yang sangat panjang.
+	MaxPasswordLength = 128
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = loadCommonPasswords(commonPasswordList)
+
+func loadCommonPasswords(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+
+	return set
+}
+
+// IsCommonPassword mengecek password terhadap daftar offline password bocor.
+func IsCommonPassword(password string) bool {
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
+
+// minPasswordLength bisa dinaikkan lewat PASSWORD_MIN_LENGTH, tidak bisa diturunkan.
+func minPasswordLength() int {
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && n > MinPasswordLength {
+		return n
+	}
+	return MinPasswordLength
+}
+
+// ValidatePassword mengecek password baru terhadap aturan minimal
+// dan daftar password umum.
+func ValidatePassword(password string) error {
+	minLength := minPasswordLength()
+
+	if len(password) < minLength {
+		return fmt.Errorf("password must be at least %d characters", minLength)
+	}
+
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("password must be at most %d characters", MaxPasswordLength)
+	}
+
+	var hasUpper, hasLower, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasUpper || !hasLower || !hasDigit {
+		return errors.New("password must contain uppercase, lowercase and digit characters")
+	}
+
+	if IsCommonPassword(password) {
+		return errors.New("password is too common, please choose another one")
+	}
+
+	return nil
+}
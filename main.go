@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 
+	"uas/app/policy"
 	"uas/app/repository"
 	"uas/app/service"
 	"uas/database"
+	"uas/helper"
 	"uas/middleware"
 	"uas/route"
 )
@@ -20,27 +24,92 @@ func main() {
 	database.ConnectDB()
 	database.ConnectMongo()
 
+	// JWT signing keys
+	jwtKeys, err := helper.LoadKeySetFromEnv()
+	if err != nil {
+		log.Fatalf("Gagal load JWT keys: %v", err)
+	}
+
 	// auth
 	userRepo := repository.NewUserRepository(database.DB)
-	authService := service.NewAuthService(userRepo)
+	revocations := repository.NewTokenRevocationRepository(database.DB)
+	middleware.StartRevocationPurger(context.Background(), revocations, time.Hour)
+
+	refreshTokenRepo := repository.NewRefreshTokenRepository(database.DB)
+	securityEventRepo := repository.NewSecurityEventRepository(database.DB)
+	passwordResetRepo := repository.NewPasswordResetRepository(database.DB)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(database.DB)
+	mfaRepo := repository.NewMFARepository(database.DB)
+	roleRepo := repository.NewRoleRepository(database.DB)
+	identityRepo := repository.NewIdentityRepository(database.DB)
+	permissionRepo := repository.NewPermissionRepository(database.DB)
+	orgUnitRepo := repository.NewOrgUnitRepository(database.DB)
+	mailer := helper.NewMailerFromEnv()
+	passwordHasher := helper.NewPasswordHasherFromEnv()
+
+	authService := service.NewAuthService(
+		userRepo,
+		revocations,
+		refreshTokenRepo,
+		securityEventRepo,
+		passwordResetRepo,
+		mailer,
+		loginAttemptRepo,
+		mfaRepo,
+		roleRepo,
+		permissionRepo,
+		jwtKeys,
+		helper.NewOIDCProviderFromEnv(),
+		identityRepo,
+		service.NewAuthProvidersFromEnv(userRepo, roleRepo, passwordHasher),
+		passwordHasher,
+	)
 
 	// admin user
 	adminRepo := repository.NewAdminUserRepository(database.DB)
-	adminUserService := service.NewAdminUserService(adminRepo)
+	auditLogRepo := repository.NewAuditLogRepository(database.DB)
+	adminUserService := service.NewAdminUserService(adminRepo, securityEventRepo, auditLogRepo, jwtKeys, passwordHasher, orgUnitRepo)
+	permissionCache := middleware.NewPermissionCacheFromEnv(permissionRepo)
+	roleSvc := service.NewRoleService(roleRepo, permissionRepo, permissionCache)
+	permissionSvc := service.NewPermissionService(permissionRepo, permissionCache)
+
+	serviceAccountRepo := repository.NewServiceAccountRepository(database.DB)
+	serviceAccountSvc := service.NewServiceAccountService(serviceAccountRepo, permissionRepo, adminRepo)
+
+	registrationRepo := repository.NewRegistrationRepository(database.DB)
+	registrationSvc := service.NewRegistrationService(registrationRepo, userRepo, roleRepo, jwtKeys, mailer, passwordHasher)
 
 	// student & lecturer repo
 	studentRepo := repository.NewStudentRepository(database.DB)
 	lecturerRepo := repository.NewLecturerRepository(database.DB)
 
+	// policy otorisasi level resource (owner / dosen wali / admin)
+	delegationRepo := repository.NewDelegationRepository(database.DB)
+	approvalRepo := repository.NewApprovalRepository(database.DB)
+	policies := policy.NewResolver(studentRepo, lecturerRepo, permissionCache, delegationRepo, approvalRepo, orgUnitRepo)
+
 	// achievement repo (PG + Mongo)
 	achievementPGRepo := repository.NewAchievementRepository(database.DB)
 	achievementMongoRepo := repository.NewMongoAchievementRepository(database.Mongo)
+	submissionRepo := repository.NewSubmissionRepository(database.DB)
+	approvalChainRepo := repository.NewApprovalChainRepository(database.DB)
+	transitionRepo := repository.NewTransitionRepository(database.DB)
+	versionRepo := repository.NewAchievementVersionRepository(database.Mongo)
+	if err := versionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Println("WARNING achievement_versions index:", err)
+	}
 
 	// achievement services
 	studentAch := service.NewStudentAchievementService(
 		achievementPGRepo,
 		studentRepo,
 		achievementMongoRepo,
+		submissionRepo,
+		versionRepo,
+		approvalRepo,
+		approvalChainRepo,
+		transitionRepo,
+		policies,
 	)
 
 	lecturerAch := service.NewLecturerAchievementService(
@@ -48,33 +117,60 @@ func main() {
 		studentRepo,
 		lecturerRepo,
 		achievementMongoRepo,
+		submissionRepo,
+		versionRepo,
+		approvalRepo,
+		approvalChainRepo,
+		transitionRepo,
+		policies,
 	)
 
 	// student & lecturer services
 	studentSvc := service.NewStudentService(
 		studentRepo,
 		achievementPGRepo,
+		lecturerRepo,
+		policies,
 	)
 
 	lecturerSvc := service.NewLecturerService(
 		lecturerRepo,
 	)
 
+	orgUnitSvc := service.NewOrgUnitService(orgUnitRepo, studentRepo, lecturerRepo)
+	delegationSvc := service.NewDelegationService(delegationRepo, lecturerRepo, policies)
+
+	approvalSvc := service.NewApprovalService(
+		achievementPGRepo,
+		achievementMongoRepo,
+		submissionRepo,
+		approvalRepo,
+		approvalChainRepo,
+		transitionRepo,
+		policies,
+	)
+	approvalChainSvc := service.NewApprovalChainService(approvalChainRepo, permissionRepo)
+
+	commentRepo := repository.NewCommentRepository(database.DB)
+	commentSvc := service.NewCommentService(achievementPGRepo, achievementMongoRepo, commentRepo, policies)
+
 	adminAchievementSvc := service.NewAdminAchievementService(
 		achievementPGRepo,
 		achievementMongoRepo,
 	)
 
-	jwt := middleware.NewJWTMiddleware(userRepo)
-	rbac := middleware.NewRBACMiddleware(adminRepo)
+	jwt := middleware.NewJWTMiddleware(userRepo, jwtKeys, serviceAccountRepo, orgUnitRepo)
+	rbac := middleware.NewRBACMiddleware(permissionCache)
 
 	reportSvc := service.NewReportService(
 		achievementPGRepo,
 		achievementMongoRepo,
 		studentRepo,
 		lecturerRepo,
+		policies,
 	)
 	app := fiber.New()
+	app.Use(middleware.NewAuditMiddleware(auditLogRepo).Log)
 
 	route.RegisterRoutes(
 		app,
@@ -88,6 +184,15 @@ func main() {
 		lecturerSvc,
 		adminAchievementSvc,
 		reportSvc,
+		roleSvc,
+		serviceAccountSvc,
+		registrationSvc,
+		permissionSvc,
+		orgUnitSvc,
+		delegationSvc,
+		approvalSvc,
+		approvalChainSvc,
+		commentSvc,
 	)
 
 	app.Static("/uploads", "./uploads")
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+	"uas/app/models"
+	"uas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requireAPIKey mengautentikasi "Authorization: ApiKey uas_..." milik service account.
+// Permission dibatasi oleh scopes pada key (lihat RBACMiddleware).
+func (m *JWTMiddleware) requireAPIKey(c *fiber.Ctx, rawKey string) error {
+	prefix, ok := helper.ParseAPIKey(rawKey)
+	if !ok {
+		return helper.Error(c, 401, "invalid API key")
+	}
+
+	key, err := m.serviceAccounts.FindKeyByPrefix(c.Context(), prefix)
+	if err != nil {
+		return helper.Error(c, 401, "invalid API key")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(helper.HashToken(rawKey))) != 1 {
+		return helper.Error(c, 401, "invalid API key")
+	}
+
+	if key.RevokedAt != nil {
+		return helper.Error(c, 401, "API key revoked")
+	}
+
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return helper.Error(c, 401, "API key expired")
+	}
+
+	account, err := m.serviceAccounts.FindByID(c.Context(), key.ServiceAccountID)
+	if err != nil || !account.IsActive {
+		return helper.Error(c, 401, "service account is not active")
+	}
+
+	if err := m.serviceAccounts.TouchKey(c.Context(), key.ID); err != nil {
+		fmt.Println("ERROR touch API key:", err)
+	}
+
+	// handler lama membaca c.Locals("user"), jadi service account
+	// direpresentasikan sebagai user tanpa role
+	c.Locals("userID", account.ID.String())
+	c.Locals("user", models.Users{
+		ID:       account.ID,
+		Username: "svc:" + account.Name,
+		FullName: account.Name,
+		IsActive: true,
+	})
+	c.Locals("serviceAccount", account)
+	c.Locals("apiKeyScopes", key.Scopes)
+
+	return c.Next()
+}
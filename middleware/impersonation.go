@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"uas/app/models"
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DenyImpersonation menolak aksi sensitif (ganti password, role, MFA, ...)
+// kalau request datang dari token impersonation.
+func DenyImpersonation(c *fiber.Ctx) error {
+	if _, ok := c.Locals("impersonator").(models.Users); ok {
+		return c.Status(403).JSON(fiber.Map{
+			"status":  "error",
+			"message": "forbidden: action not allowed while impersonating",
+		})
+	}
+	return c.Next()
+}
+
+type AuditMiddleware struct {
+	logs repository.AuditLogRepository
+}
+
+func NewAuditMiddleware(logs repository.AuditLogRepository) *AuditMiddleware {
+	return &AuditMiddleware{logs}
+}
+
+// Log dipasang global (app.Use). Locals di-set oleh RequireAuth di handler
+// berikutnya, jadi dibaca setelah c.Next() selesai.
+func (m *AuditMiddleware) Log(c *fiber.Ctx) error {
+	err := c.Next()
+
+	impersonator, ok := c.Locals("impersonator").(models.Users)
+	if !ok {
+		return err
+	}
+
+	user, _ := c.Locals("user").(models.Users)
+
+	status := c.Response().StatusCode()
+	if fe, ok := err.(*fiber.Error); ok {
+		status = fe.Code
+	}
+
+	if logErr := m.logs.Record(c.Context(), models.AuditLog{
+		ID:             uuid.New(),
+		UserID:         user.ID,
+		ImpersonatorID: &impersonator.ID,
+		Method:         c.Method(),
+		Path:           c.OriginalURL(),
+		Status:         status,
+		IP:             c.IP(),
+	}); logErr != nil {
+		fmt.Println("ERROR audit log:", logErr)
+	}
+
+	return err
+}
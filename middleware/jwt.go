@@ -1,85 +1,138 @@
 package middleware
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"uas/app/repository"
 	"uas/helper"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTMiddleware struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	keys            *helper.KeySet
+	serviceAccounts repository.ServiceAccountRepository
+	orgUnits        repository.OrgUnitRepository
 }
 
-func NewJWTMiddleware(repo repository.UserRepository) *JWTMiddleware {
-	return &JWTMiddleware{repo}
+func NewJWTMiddleware(
+	repo repository.UserRepository,
+	keys *helper.KeySet,
+	serviceAccounts repository.ServiceAccountRepository,
+	orgUnits repository.OrgUnitRepository,
+) *JWTMiddleware {
+	return &JWTMiddleware{repo, keys, serviceAccounts, orgUnits}
 }
 
 func (m *JWTMiddleware) RequireAuth(c *fiber.Ctx) error {
 	authHeader := c.Get("Authorization")
 
-	fmt.Println("=== DEBUG MIDDLEWARE ===")
-	fmt.Println("Authorization Header:", authHeader)
-
 	if authHeader == "" {
 		return helper.Error(c, 401, "missing Authorization header")
 	}
 
-	tokenStr := strings.Replace(authHeader, "Bearer ", "", 1)
-	fmt.Println("Extracted Token:", tokenStr)
-
-	if IsTokenBlacklisted(tokenStr) {
-		fmt.Println("TOKEN IS BLACKLISTED")
-		return helper.Error(c, 401, "token already logged out")
+	if rawKey, ok := strings.CutPrefix(authHeader, "ApiKey "); ok {
+		return m.requireAPIKey(c, strings.TrimSpace(rawKey))
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "DEFAULT_SECRET"
-	}
-
-	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	tokenStr := strings.Replace(authHeader, "Bearer ", "", 1)
 
+	// refresh / MFA token ditandatangani kunci yang sama; Parse menolaknya
+	// lewat aud dan typ
+	claims, err := m.keys.Parse(helper.TokenAccess, tokenStr)
 	if err != nil {
 		fmt.Println("JWT Parse Error:", err)
 		return helper.Error(c, 401, "invalid or expired token")
 	}
 
-	if !token.Valid {
-		fmt.Println("Token invalid")
-		return helper.Error(c, 401, "invalid token")
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		fmt.Println("ERROR: invalid claims type")
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		fmt.Println("ERROR: jti claim missing")
 		return helper.Error(c, 401, "invalid token claims")
 	}
 
-	fmt.Println("Claims:", claims)
-
 	userID, ok := claims["id"].(string)
-	if !ok {
-		fmt.Println("ERROR: id claim missing or not string")
+	if _, err := uuid.Parse(userID); !ok || err != nil {
+		fmt.Println("ERROR: id claim missing or not a uuid")
 		return helper.Error(c, 401, "invalid token claims")
 	}
 
 	fmt.Println("UserID from claims:", userID)
 
-	user, err := m.userRepo.FindByID(c.Context(), userID)
-	if err != nil {
-		fmt.Println("ERROR DB FindByID:", err)
+	// session (refresh family) yang dicabut ikut mematikan access token-nya.
+	// User dan revocation dibaca dalam satu query dan tidak di-cache, supaya
+	// logout, nonaktif, ganti role / password langsung berlaku di semua instance.
+	ids := []string{jti}
+	if sid, ok := claims["sid"].(string); ok {
+		ids = append(ids, sid)
+	}
+
+	user, revoked, err := m.userRepo.FindForToken(c.Context(), userID, ids...)
+	if errors.Is(err, sql.ErrNoRows) {
 		return helper.Error(c, 401, "user not found")
 	}
+	if err != nil {
+		fmt.Println("ERROR load token user:", err)
+		return helper.Error(c, 500, "failed to check token")
+	}
+
+	if revoked {
+		fmt.Println("TOKEN IS REVOKED")
+		return helper.Error(c, 401, "token already logged out")
+	}
 
 	fmt.Println("Loaded User:", user.Username)
 
+	// akun nonaktif / role atau password berubah: token lama langsung ditolak
+	if !user.IsActive {
+		return helper.Error(c, 401, "account is not active")
+	}
+
+	if !helper.TokenVersionMatches(claims, user.TokenVersion) {
+		fmt.Println("TOKEN VERSION OUTDATED")
+		return helper.Error(c, 401, "token is no longer valid, please login again")
+	}
+
+	// token impersonation: "id" adalah user yang dilihat,
+	// admin yang sebenarnya ada di claim "act"
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		adminID, _ := act["sub"].(string)
+
+		admin, err := m.userRepo.FindByID(c.Context(), adminID)
+		if err != nil || !admin.IsActive || !helper.TokenVersionMatches(act, admin.TokenVersion) {
+			fmt.Println("ERROR impersonator not found or inactive:", adminID)
+			return helper.Error(c, 401, "invalid impersonation token")
+		}
+
+		// scope di bawah ini milik user target; admin unit tidak boleh
+		// memakainya untuk melihat data di luar unit-nya
+		if admin.ScopeOrgUnitID != nil {
+			fmt.Println("ERROR impersonator is unit-scoped:", adminID)
+			return helper.Error(c, 401, "invalid impersonation token")
+		}
+
+		c.Locals("impersonatorID", adminID)
+		c.Locals("impersonator", admin)
+	}
+
+	// role yang di-scope ke unit: repository memfilter listing lewat ctx
+	if user.ScopeOrgUnitID != nil {
+		units, err := m.orgUnits.Descendants(c.Context(), *user.ScopeOrgUnitID)
+		if err != nil {
+			fmt.Println("ERROR load org scope:", err)
+			return helper.Error(c, 500, "failed to load organization scope")
+		}
+
+		c.Locals(repository.OrgScopeKey, repository.OrgScope{
+			RootID:  *user.ScopeOrgUnitID,
+			UnitIDs: units,
+		})
+	}
+
 	c.Locals("userID", userID)
 	c.Locals("user", user)
 	c.Locals("claims", claims)
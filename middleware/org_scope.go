@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"uas/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireGlobalScope menolak admin yang role-nya di-scope ke unit tertentu.
+// Dipakai untuk konfigurasi yang berlaku ke seluruh universitas (role,
+// permission, service account).
+func RequireGlobalScope(c *fiber.Ctx) error {
+	if _, ok := repository.OrgScopeFromContext(c.Context()); ok {
+		return c.Status(403).JSON(fiber.Map{
+			"status":  "error",
+			"message": "forbidden: requires a university-wide administrator",
+		})
+	}
+	return c.Next()
+}
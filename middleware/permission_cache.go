@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"uas/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// PermissionCache menyimpan permission per role di memori selama ttl.
+// Perubahan role_permissions / permissions harus memanggil Invalidate atau
+// InvalidateAll; instance lain akan ikut konsisten setelah ttl habis.
+// Perubahan role milik user tidak perlu invalidasi karena role_id selalu
+// dibaca ulang oleh JWTMiddleware.
+type PermissionCache struct {
+	perms repository.PermissionRepository
+	ttl   time.Duration
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]permissionCacheEntry
+}
+
+type permissionCacheEntry struct {
+	names     map[string]bool
+	expiresAt time.Time
+}
+
+func NewPermissionCache(perms repository.PermissionRepository, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		perms:   perms,
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]permissionCacheEntry),
+	}
+}
+
+// NewPermissionCacheFromEnv memakai PERMISSION_CACHE_TTL_SECONDS (default 60).
+func NewPermissionCacheFromEnv(perms repository.PermissionRepository) *PermissionCache {
+	ttl := 60 * time.Second
+	if n, err := strconv.Atoi(os.Getenv("PERMISSION_CACHE_TTL_SECONDS")); err == nil && n >= 0 {
+		ttl = time.Duration(n) * time.Second
+	}
+	return NewPermissionCache(perms, ttl)
+}
+
+// Get mengembalikan set nama permission milik role.
+func (c *PermissionCache) Get(ctx context.Context, roleID uuid.UUID) (map[string]bool, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[roleID]
+	c.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.names, nil
+	}
+
+	list, err := c.perms.FindByRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(list))
+	for _, p := range list {
+		names[p.Name] = true
+	}
+
+	c.mu.Lock()
+	c.entries[roleID] = permissionCacheEntry{names: names, expiresAt: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return names, nil
+}
+
+func (c *PermissionCache) Invalidate(roleID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.entries, roleID)
+	c.mu.Unlock()
+}
+
+func (c *PermissionCache) InvalidateAll() {
+	c.mu.Lock()
+	c.entries = make(map[uuid.UUID]permissionCacheEntry)
+	c.mu.Unlock()
+}
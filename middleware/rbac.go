@@ -1,23 +1,36 @@
 package middleware
 
 import (
-	// "context"
-
-	"uas/app/repository"
+	"strings"
+	"uas/app/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type RBACMiddleware struct {
-	users repository.AdminUserRepository
+	cache *PermissionCache
 }
 
-func NewRBACMiddleware(userRepo repository.AdminUserRepository) *RBACMiddleware {
-	return &RBACMiddleware{users: userRepo}
+func NewRBACMiddleware(cache *PermissionCache) *RBACMiddleware {
+	return &RBACMiddleware{cache: cache}
 }
 
 // RequirePermission("user:manage")
 func (m *RBACMiddleware) RequirePermission(perm string) fiber.Handler {
+	return m.require(true, perm)
+}
+
+// RequireAnyPermission lolos kalau user punya minimal satu permission.
+func (m *RBACMiddleware) RequireAnyPermission(perms ...string) fiber.Handler {
+	return m.require(false, perms...)
+}
+
+// RequireAllPermissions lolos kalau user punya semua permission.
+func (m *RBACMiddleware) RequireAllPermissions(perms ...string) fiber.Handler {
+	return m.require(true, perms...)
+}
+
+func (m *RBACMiddleware) require(all bool, perms ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userIDValue := c.Locals("userID")
 		userID, ok := userIDValue.(string)
@@ -28,26 +41,48 @@ func (m *RBACMiddleware) RequirePermission(perm string) fiber.Handler {
 			})
 		}
 
-		perms, err := m.users.GetUserPermissions(c.Context(), userID)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "failed to load permissions",
-			})
+		var granted map[string]bool
+
+		// API key: permission = scopes yang diberikan ke key tersebut
+		if scopes, ok := c.Locals("apiKeyScopes").([]string); ok {
+			granted = make(map[string]bool, len(scopes))
+			for _, s := range scopes {
+				granted[s] = true
+			}
+		} else {
+			user, ok := c.Locals("user").(models.Users)
+			if !ok {
+				return c.Status(401).JSON(fiber.Map{
+					"status":  "error",
+					"message": "unauthorized: missing user",
+				})
+			}
+
+			var err error
+			granted, err = m.cache.Get(c.Context(), user.RoleID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"status":  "error",
+					"message": "failed to load permissions",
+				})
+			}
 		}
 
-		hasPerm := false
+		var missing []string
 		for _, p := range perms {
-			if p == perm {
-				hasPerm = true
-				break
+			if granted[p] {
+				if !all {
+					return c.Next()
+				}
+				continue
 			}
+			missing = append(missing, p)
 		}
 
-		if !hasPerm {
+		if len(missing) > 0 {
 			return c.Status(403).JSON(fiber.Map{
 				"status":  "error",
-				"message": "forbidden: missing permission " + perm,
+				"message": "forbidden: missing permission " + strings.Join(missing, ", "),
 			})
 		}
 
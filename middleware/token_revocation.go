@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// TokenRevocationStore menyimpan jti token yang sudah dicabut (logout)
+// sampai token tersebut kadaluarsa.
+type TokenRevocationStore interface {
+	Revoke(ctx context.Context, jti string, exp time.Time) error
+	IsRevoked(ctx context.Context, jtis ...string) (bool, error)
+	PurgeExpired(ctx context.Context) error
+}
+
+// memoryRevocationStore dipakai untuk test / single instance.
+type memoryRevocationStore struct {
+	sync.RWMutex
+	data map[string]time.Time
+}
+
+func NewMemoryRevocationStore() TokenRevocationStore {
+	return &memoryRevocationStore{
+		data: make(map[string]time.Time),
+	}
+}
+
+func (s *memoryRevocationStore) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	s.Lock()
+	s.data[jti] = exp
+	s.Unlock()
+	return nil
+}
+
+func (s *memoryRevocationStore) IsRevoked(ctx context.Context, jtis ...string) (bool, error) {
+	now := time.Now()
+
+	s.RLock()
+	defer s.RUnlock()
+
+	// entry kadaluarsa dibiarkan untuk PurgeExpired
+	for _, jti := range jtis {
+		if exp, ok := s.data[jti]; ok && now.Before(exp) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *memoryRevocationStore) PurgeExpired(ctx context.Context) error {
+	now := time.Now()
+
+	s.Lock()
+	for jti, exp := range s.data {
+		if now.After(exp) {
+			delete(s.data, jti)
+		}
+	}
+	s.Unlock()
+
+	return nil
+}
+
+// StartRevocationPurger menghapus entry yang sudah kadaluarsa secara berkala
+// sampai ctx dibatalkan.
+func StartRevocationPurger(ctx context.Context, store TokenRevocationStore, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := store.PurgeExpired(ctx); err != nil {
+					log.Println("failed purge revoked tokens:", err)
+				}
+			}
+		}
+	}()
+}
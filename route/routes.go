@@ -19,8 +19,19 @@ func RegisterRoutes(
 	lecturerSvc service.LecturerService,
 	adminAchievementSvc service.AdminAchievementService,
 	reportSvc service.ReportService,
+	roleSvc service.RoleService,
+	serviceAccountSvc service.ServiceAccountService,
+	registrationSvc service.RegistrationService,
+	permissionSvc service.PermissionService,
+	orgUnitSvc service.OrgUnitService,
+	delegationSvc service.DelegationService,
+	approvalSvc service.ApprovalService,
+	approvalChainSvc service.ApprovalChainService,
+	commentSvc service.CommentService,
 ) {
 
+	app.Get("/.well-known/jwks.json", auth.JWKSHandler)
+
 	api := app.Group("/app")
 
 	// auth
@@ -29,16 +40,87 @@ func RegisterRoutes(
 	authRoute.Post("/refresh", auth.RefreshHandler)
 	authRoute.Get("/profile", jwt.RequireAuth, auth.ProfileHandler)
 	authRoute.Post("/logout", jwt.RequireAuth, auth.Logout)
-
-	// users
+	authRoute.Get("/sessions", jwt.RequireAuth, auth.ListSessions)
+	authRoute.Delete("/sessions", jwt.RequireAuth, middleware.DenyImpersonation, auth.RevokeAllSessions)
+	authRoute.Delete("/sessions/:id", jwt.RequireAuth, middleware.DenyImpersonation, auth.RevokeSession)
+	authRoute.Post("/password", jwt.RequireAuth, middleware.DenyImpersonation, auth.ChangePassword)
+	authRoute.Post("/password/forgot", auth.ForgotPassword)
+	authRoute.Post("/password/reset", auth.ResetPassword)
+	authRoute.Post("/mfa/enroll", jwt.RequireAuth, middleware.DenyImpersonation, auth.EnrollMFA)
+	authRoute.Post("/mfa/enroll/confirm", jwt.RequireAuth, middleware.DenyImpersonation, auth.ConfirmMFA)
+	authRoute.Delete("/mfa", jwt.RequireAuth, middleware.DenyImpersonation, auth.DisableMFA)
+	authRoute.Post("/mfa/challenge/enroll", auth.ChallengeEnrollMFA)
+	authRoute.Post("/mfa/verify", auth.VerifyMFA)
+	authRoute.Get("/oidc/login", auth.OIDCLogin)
+	authRoute.Get("/oidc/callback", auth.OIDCCallback)
+	authRoute.Post("/register", registrationSvc.Register)
+	authRoute.Get("/register/verify", registrationSvc.VerifyEmail)
+	authRoute.Post("/register/verify", registrationSvc.VerifyEmail)
+
+	// users (semua aksi admin ditolak selama impersonation)
 	users := api.Group("/users")
 
-	users.Get("/", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.GetAll)
-	users.Get("/:id", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.GetByID)
-	users.Post("/", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.Create)
-	users.Put("/:id", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.Update)
-	users.Delete("/:id", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.Delete)
-	users.Put("/:id/role", jwt.RequireAuth, rbac.RequirePermission("user:manage"), adminUser.UpdateRole)
+	users.Get("/", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.GetAll)
+	users.Get("/:id", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.GetByID)
+	users.Post("/", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.Create)
+	users.Put("/:id", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.Update)
+	users.Delete("/:id", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.Delete)
+	users.Put("/:id/role", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.UpdateRole)
+	users.Post("/:id/unlock", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.Unlock)
+	users.Get("/:id/security-events", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.GetSecurityEvents)
+	users.Get("/:id/audit-logs", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.GetAuditLogs)
+	users.Get("/:id/permissions", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), permissionSvc.GetUserPermissions)
+	users.Post("/:id/impersonate", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), adminUser.Impersonate)
+
+	// roles
+	roles := api.Group("/roles", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), middleware.RequireGlobalScope)
+
+	roles.Get("/", roleSvc.GetAll)
+	roles.Get("/:id", roleSvc.GetByID)
+	roles.Post("/", roleSvc.Create)
+	roles.Put("/:id", roleSvc.Update)
+	roles.Delete("/:id", roleSvc.Delete)
+	roles.Post("/:id/permissions/:permissionId", roleSvc.AttachPermission)
+	roles.Delete("/:id/permissions/:permissionId", roleSvc.DetachPermission)
+	roles.Put("/:id/mfa-policy", roleSvc.UpdateMFAPolicy)
+
+	// permissions
+	permissions := api.Group("/permissions", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), middleware.RequireGlobalScope)
+
+	permissions.Get("/", permissionSvc.GetAll)
+	permissions.Post("/", permissionSvc.Create)
+	permissions.Put("/:id", permissionSvc.Update)
+	permissions.Delete("/:id", permissionSvc.Delete)
+
+	// service accounts (API key untuk integrasi)
+	serviceAccounts := api.Group("/service-accounts", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), middleware.RequireGlobalScope)
+
+	serviceAccounts.Get("/", serviceAccountSvc.GetAll)
+	serviceAccounts.Post("/", serviceAccountSvc.Create)
+	serviceAccounts.Delete("/:id", serviceAccountSvc.Deactivate)
+	serviceAccounts.Get("/:id/keys", serviceAccountSvc.ListKeys)
+	serviceAccounts.Post("/:id/keys", serviceAccountSvc.CreateKey)
+	serviceAccounts.Delete("/:id/keys/:keyId", serviceAccountSvc.RevokeKey)
+
+	// unit organisasi (universitas -> fakultas -> departemen)
+	// sengaja tanpa RequireGlobalScope: admin unit mengelola sub-unit di
+	// bawah unit-nya sendiri; FindAll memakai scopeFilter dan setiap handler
+	// tulis mengecek scope lewat scopedUnit / inOrgScope
+	orgUnits := api.Group("/org-units", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"))
+
+	orgUnits.Get("/", orgUnitSvc.GetAll)
+	orgUnits.Post("/", orgUnitSvc.Create)
+	orgUnits.Put("/:id", orgUnitSvc.Update)
+	orgUnits.Delete("/:id", orgUnitSvc.Delete)
+	orgUnits.Put("/:id/students/:studentId", orgUnitSvc.AssignStudent)
+	orgUnits.Put("/:id/lecturers/:lecturerId", orgUnitSvc.AssignLecturer)
+
+	// pendaftaran mahasiswa (antrian persetujuan admin)
+	registrations := api.Group("/registrations", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), middleware.RequireGlobalScope)
+
+	registrations.Get("/", registrationSvc.GetPending)
+	registrations.Post("/:id/approve", registrationSvc.Approve)
+	registrations.Post("/:id/reject", registrationSvc.Reject)
 
 	// achievements
 	achievement := api.Group("student/achievements", jwt.RequireAuth)
@@ -47,6 +129,10 @@ func RegisterRoutes(
 	achievement.Post("/", rbac.RequirePermission("achievement:create"), studentAch.Create)
 	achievement.Get("/:id", rbac.RequirePermission("achievement:create"), studentAch.GetDetail)
 	achievement.Get("/:id/history", rbac.RequirePermission("achievement:create"), studentAch.GetHistory)
+	achievement.Get("/:id/submissions", rbac.RequirePermission("achievement:create"), studentAch.GetSubmissions)
+	achievement.Get("/:id/versions", rbac.RequirePermission("achievement:create"), studentAch.GetVersions)
+	achievement.Get("/:id/versions/diff", rbac.RequirePermission("achievement:create"), studentAch.DiffVersions)
+	achievement.Get("/:id/versions/:version", rbac.RequirePermission("achievement:create"), studentAch.GetVersion)
 	achievement.Put("/:id", rbac.RequirePermission("achievement:update"), studentAch.Update)
 	achievement.Delete("/:id", rbac.RequirePermission("achievement:delete"), studentAch.Delete)
 	achievement.Post("/:id/submit", rbac.RequirePermission("achievement:submit"), studentAch.Submit)
@@ -54,10 +140,44 @@ func RegisterRoutes(
 
 	lecturer := api.Group("lecturer/achievements", jwt.RequireAuth)
 	lecturer.Get("/", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetAdviseeAchievements)
+	lecturer.Post("/bulk", rbac.RequireAnyPermission("achievement:verify", "achievement:reject"), lecturerAch.BulkReview)
 	lecturer.Get("/:id", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetDetail)
 	lecturer.Get("/:id/history", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetHistory)
+	lecturer.Get("/:id/submissions", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetSubmissions)
+	lecturer.Get("/:id/versions", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetVersions)
+	lecturer.Get("/:id/versions/diff", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.DiffVersions)
+	lecturer.Get("/:id/versions/:version", rbac.RequirePermission("achievement:read_advisee"), lecturerAch.GetVersion)
 	lecturer.Post("/:id/verify", rbac.RequirePermission("achievement:verify"), lecturerAch.Verify)
 	lecturer.Post("/:id/reject", rbac.RequirePermission("achievement:reject"), lecturerAch.Reject)
+	lecturer.Post("/:id/request-revision", rbac.RequireAnyPermission("achievement:verify", "achievement:reject"), lecturerAch.RequestRevision)
+
+	// approval chain bertingkat; hak tiap stage dicek lewat policy
+	approvals := api.Group("/approvals", jwt.RequireAuth)
+	approvals.Get("/queue", approvalSvc.GetQueue)
+	approvals.Get("/:id", approvalSvc.GetDetail)
+	approvals.Post("/:id/approve", approvalSvc.Approve)
+	approvals.Post("/:id/reject", approvalSvc.Reject)
+	approvals.Post("/:id/request-revision", approvalSvc.RequestRevision)
+
+	approvalChains := api.Group("/approval-chains", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequirePermission("user:manage"), middleware.RequireGlobalScope)
+	approvalChains.Get("/", approvalChainSvc.GetAll)
+	approvalChains.Post("/", approvalChainSvc.Create)
+	approvalChains.Put("/:id", approvalChainSvc.Update)
+	approvalChains.Delete("/:id", approvalChainSvc.Delete)
+
+	// komentar prestasi (pemilik & reviewer); akses dicek lewat policy
+	comments := api.Group("/achievements/:id/comments", jwt.RequireAuth)
+	comments.Get("/", commentSvc.GetAll)
+	comments.Post("/", commentSvc.Create)
+	comments.Put("/:commentId", commentSvc.Update)
+	comments.Delete("/:commentId", commentSvc.Delete)
+	comments.Get("/:commentId/history", commentSvc.GetHistory)
+
+	// delegasi verifikasi (dosen sendiri, atau admin atas nama dosen)
+	delegations := api.Group("lecturer/delegations", jwt.RequireAuth, middleware.DenyImpersonation, rbac.RequireAnyPermission("achievement:verify", "user:manage"))
+	delegations.Get("/", delegationSvc.GetAll)
+	delegations.Post("/", delegationSvc.Create)
+	delegations.Delete("/:id", delegationSvc.Revoke)
 
 	// students
 	students := api.Group("/students", jwt.RequireAuth)
@@ -82,7 +202,7 @@ func RegisterRoutes(
 	// reports
 	reports := api.Group("/reports", jwt.RequireAuth)
 
-	reports.Get("/statistics", rbac.RequirePermission("user:manage"), reportSvc.GetStatistics)
+	reports.Get("/statistics", rbac.RequirePermission("report:read"), reportSvc.GetStatistics)
 	reports.Get("/student/:id", reportSvc.GetStudentStatistics)
 
 }